@@ -0,0 +1,276 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// badEncodingProofVersion is the version of the binary serialization of a
+// BadEncodingProof.
+const badEncodingProofVersion = 1
+
+// ErrInvalidBadEncodingProof is returned when a BadEncodingProof does not
+// prove that a row or column was incorrectly encoded.
+var ErrInvalidBadEncodingProof = errors.New("invalid bad encoding proof")
+
+// BadEncodingProof proves that a row or column of an extended data square is
+// not a valid encoding of its original data, or that it does not match its
+// root. It contains the shares of the row or column that are known to the
+// prover, each with an inclusion proof against the root of the orthogonal
+// column or row. Since the row or column root itself is untrusted, the
+// inclusion of each share is proven along the orthogonal axis.
+type BadEncodingProof struct {
+	// Axis describes if the proof is for a row or column.
+	Axis Axis
+	// Index is the row or column index.
+	Index uint
+	// Proofs contains, for every cell of the row or column, the share and its
+	// inclusion proof against the orthogonal axis root. Missing shares are nil.
+	Proofs []*ShareProof
+}
+
+// ProveBadEncoding builds a BadEncodingProof from an ErrByzantineData returned
+// by Repair, e.g. once more of the square is known if Repair could not attach
+// the proof itself. Shares can only be proven if the orthogonal row or column they
+// belong to is complete in the square; the remaining shares are omitted.
+// Returns an error if too few shares can be proven to reconstruct the row or
+// column.
+func (eds *ExtendedDataSquare) ProveBadEncoding(byzErr *ErrByzantineData) (*BadEncodingProof, error) {
+	orthogonal := Col
+	length := eds.width
+	originalLength := eds.originalDataWidth
+	if byzErr.Axis == Col {
+		orthogonal = Row
+		length = eds.height
+		originalLength = eds.originalDataHeight
+	}
+	if uint(len(byzErr.Shares)) != length {
+		return nil, fmt.Errorf("expected %d shares for %s %d, got %d", length, byzErr.Axis, byzErr.Index, len(byzErr.Shares))
+	}
+
+	proofs := make([]*ShareProof, length)
+	proven := uint(0)
+	for i, share := range byzErr.Shares {
+		if share == nil {
+			continue
+		}
+		proof, err := eds.ProveShare(orthogonal, uint(i), byzErr.Index)
+		if err != nil {
+			// the orthogonal row or column is incomplete
+			continue
+		}
+		if !bytes.Equal(proof.Share, share) {
+			return nil, fmt.Errorf("share %d of %s %d does not match the square", i, byzErr.Axis, byzErr.Index)
+		}
+		proofs[i] = proof
+		proven++
+	}
+	if proven < originalLength {
+		return nil, fmt.Errorf("only %d shares of %s %d can be proven, need %d", proven, byzErr.Axis, byzErr.Index, originalLength)
+	}
+
+	return &BadEncodingProof{
+		Axis:   byzErr.Axis,
+		Index:  byzErr.Index,
+		Proofs: proofs,
+	}, nil
+}
+
+// attachBadEncodingProofs sets the Proof of every ErrByzantineData in err,
+// either a single one or those of an ErrByzantineAxes, whose shares can be
+// proven with the square. It returns err.
+func (eds *ExtendedDataSquare) attachBadEncodingProofs(err error) error {
+	var byzAxes *ErrByzantineAxes
+	if errors.As(err, &byzAxes) {
+		for _, byzErr := range byzAxes.Errors {
+			eds.attachBadEncodingProof(byzErr)
+		}
+		return err
+	}
+	var byzErr *ErrByzantineData
+	if errors.As(err, &byzErr) {
+		eds.attachBadEncodingProof(byzErr)
+	}
+	return err
+}
+
+// attachBadEncodingProof sets the Proof of byzErr if it is not set yet and the
+// row or column can be proven Byzantine.
+func (eds *ExtendedDataSquare) attachBadEncodingProof(byzErr *ErrByzantineData) {
+	if byzErr.Proof != nil || byzErr.Shares == nil {
+		return
+	}
+	if proof, err := eds.ProveBadEncoding(byzErr); err == nil {
+		byzErr.Proof = proof
+	}
+}
+
+// Verify checks the proof against the row and column roots of an extended
+// data square without requiring the square itself. codec must be the codec
+// used to extend the row or column, and treeCreatorFn must create trees that
+// can verify the inclusion proofs. Returns nil if the proof shows that the row
+// or column was incorrectly encoded.
+func (p *BadEncodingProof) Verify(
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+) error {
+	roots, orthogonalRoots := rowRoots, colRoots
+	orthogonal := Col
+	if p.Axis == Col {
+		roots, orthogonalRoots = colRoots, rowRoots
+		orthogonal = Row
+	}
+	if p.Index >= uint(len(roots)) {
+		return fmt.Errorf("%w: %s %d out of range", ErrInvalidBadEncodingProof, p.Axis, p.Index)
+	}
+	if len(p.Proofs) != len(orthogonalRoots) {
+		return fmt.Errorf("%w: expected %d shares, got %d", ErrInvalidBadEncodingProof, len(orthogonalRoots), len(p.Proofs))
+	}
+
+	shares := make([][]byte, len(p.Proofs))
+	for i, proof := range p.Proofs {
+		if proof == nil {
+			continue
+		}
+		if proof.Axis != orthogonal || proof.AxisIndex != uint(i) || proof.CellIndex != p.Index || proof.NumLeaves != uint(len(roots)) {
+			return fmt.Errorf("%w: proof of share %d is for the wrong cell", ErrInvalidBadEncodingProof, i)
+		}
+		if err := proof.Verify(rowRoots, colRoots, treeCreatorFn); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBadEncodingProof, err)
+		}
+		shares[i] = proof.Share
+	}
+
+	rebuiltShares, err := codec.Decode(shares)
+	if err != nil {
+		return fmt.Errorf("%w: could not decode shares: %v", ErrInvalidBadEncodingProof, err)
+	}
+
+	// If the rebuilt shares do not match the root, or the tree rejects them,
+	// e.g. because their namespaces are out of order, the row or column does
+	// not commit to a valid codeword.
+	tree := treeCreatorFn(p.Axis, p.Index)
+	for _, d := range rebuiltShares {
+		if err := tree.Push(d); err != nil {
+			return nil
+		}
+	}
+	root, err := tree.Root()
+	if err != nil || !bytes.Equal(root, roots[p.Index]) {
+		return nil
+	}
+
+	// Otherwise, the parity shares must differ from the encoded original
+	// shares.
+	originalLength := len(rebuiltShares) / int(extensionFactor(codec))
+	parityShares, err := codec.Encode(rebuiltShares[:originalLength])
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(flattenChunks(parityShares), flattenChunks(rebuiltShares[originalLength:])) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s %d is correctly encoded", ErrInvalidBadEncodingProof, p.Axis, p.Index)
+}
+
+// MarshalBinary encodes the proof in a compact binary format. The axis and
+// indices of the share proofs are implied by the proof's axis and index.
+func (p *BadEncodingProof) MarshalBinary() ([]byte, error) {
+	buf := []byte{badEncodingProofVersion, byte(p.Axis)}
+	buf = appendUvarint(buf, uint64(p.Index))
+	buf = appendUvarint(buf, uint64(len(p.Proofs)))
+	for _, proof := range p.Proofs {
+		if proof == nil {
+			buf = append(buf, 0)
+			continue
+		}
+		buf = append(buf, 1)
+		buf = appendBytes(buf, proof.Share)
+		buf = appendUvarint(buf, uint64(proof.NumLeaves))
+		buf = appendUvarint(buf, uint64(len(proof.Nodes)))
+		for _, node := range proof.Nodes {
+			buf = appendBytes(buf, node)
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a proof encoded with MarshalBinary.
+func (p *BadEncodingProof) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if header[0] != badEncodingProofVersion {
+		return fmt.Errorf("unsupported bad encoding proof version %d", header[0])
+	}
+	axis := Axis(header[1])
+	if axis != Row && axis != Col {
+		return fmt.Errorf("invalid axis type: %d", axis)
+	}
+	orthogonal := Col
+	if axis == Col {
+		orthogonal = Row
+	}
+
+	index, err := readUvarint(r, maxUint)
+	if err != nil {
+		return err
+	}
+	length, err := readUvarint(r, uint64(r.Len()))
+	if err != nil {
+		return err
+	}
+
+	proofs := make([]*ShareProof, length)
+	for i := range proofs {
+		present, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if present == 0 {
+			continue
+		}
+		proof := &ShareProof{
+			Axis:      orthogonal,
+			AxisIndex: uint(i),
+			CellIndex: uint(index),
+		}
+		if proof.Share, err = readBytes(r); err != nil {
+			return err
+		}
+		numLeaves, err := readUvarint(r, maxUint)
+		if err != nil {
+			return err
+		}
+		proof.NumLeaves = uint(numLeaves)
+		numNodes, err := readUvarint(r, uint64(r.Len()))
+		if err != nil {
+			return err
+		}
+		proof.Nodes = make([][]byte, numNodes)
+		for j := range proof.Nodes {
+			if proof.Nodes[j], err = readBytes(r); err != nil {
+				return err
+			}
+		}
+		proofs[i] = proof
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%d trailing bytes after bad encoding proof", r.Len())
+	}
+
+	*p = BadEncodingProof{
+		Axis:   axis,
+		Index:  uint(index),
+		Proofs: proofs,
+	}
+	return nil
+}
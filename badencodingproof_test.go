@@ -0,0 +1,136 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createByzantineEds returns a square whose cell (0, 0) is corrupted, along with
+// roots that commit to the corrupted square.
+func createByzantineEds(t *testing.T, codec Codec) (*ExtendedDataSquare, [][]byte, [][]byte) {
+	eds := createTestEds(codec, ShardSize)
+	eds.setCell(0, 0, bytes.Repeat([]byte{66}, ShardSize))
+
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+	return eds, rowRoots, colRoots
+}
+
+func TestBadEncodingProof(t *testing.T) {
+	codec := NewLeoRSCodec()
+	eds, rowRoots, colRoots := createByzantineEds(t, codec)
+
+	err := eds.Repair(rowRoots, colRoots)
+	var byzErr *ErrByzantineData
+	require.True(t, errors.As(err, &byzErr))
+
+	proof, err := eds.ProveBadEncoding(byzErr)
+	require.NoError(t, err)
+	assert.NoError(t, proof.Verify(rowRoots, colRoots, codec, NewDefaultTree))
+	// Repair attaches the same proof to the error
+	assert.Equal(t, proof, byzErr.Proof)
+
+	t.Run("binary round trip", func(t *testing.T) {
+		data, err := proof.MarshalBinary()
+		require.NoError(t, err)
+
+		var decoded BadEncodingProof
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.Equal(t, proof, &decoded)
+		assert.NoError(t, decoded.Verify(rowRoots, colRoots, codec, NewDefaultTree))
+
+		assert.Error(t, decoded.UnmarshalBinary(data[:len(data)-1]))
+		assert.Error(t, decoded.UnmarshalBinary(append(data, 0)))
+	})
+
+	t.Run("tampered share", func(t *testing.T) {
+		data, err := proof.MarshalBinary()
+		require.NoError(t, err)
+		var tampered BadEncodingProof
+		require.NoError(t, tampered.UnmarshalBinary(data))
+		for _, p := range tampered.Proofs {
+			if p != nil {
+				p.Share[0]++
+				break
+			}
+		}
+		assert.ErrorIs(t, tampered.Verify(rowRoots, colRoots, codec, NewDefaultTree), ErrInvalidBadEncodingProof)
+	})
+
+	t.Run("insufficient shares", func(t *testing.T) {
+		insufficient := &BadEncodingProof{Axis: proof.Axis, Index: proof.Index, Proofs: make([]*ShareProof, len(proof.Proofs))}
+		insufficient.Proofs[0] = proof.Proofs[0]
+		assert.ErrorIs(t, insufficient.Verify(rowRoots, colRoots, codec, NewDefaultTree), ErrInvalidBadEncodingProof)
+	})
+}
+
+func TestBadEncodingProofOfValidRow(t *testing.T) {
+	codec := NewLeoRSCodec()
+	eds, rowRoots, colRoots := createByzantineEds(t, codec)
+
+	// Row 1 is correctly encoded, so a proof for it must not verify.
+	proof, err := eds.ProveBadEncoding(&ErrByzantineData{Axis: Row, Index: 1, Shares: eds.Row(1)})
+	require.NoError(t, err)
+	assert.ErrorIs(t, proof.Verify(rowRoots, colRoots, codec, NewDefaultTree), ErrInvalidBadEncodingProof)
+}
+
+func TestProveBadEncodingIncompleteSquare(t *testing.T) {
+	codec := NewLeoRSCodec()
+	eds, _, _ := createByzantineEds(t, codec)
+	shares := eds.Row(0)
+
+	// With every column but one incomplete, too few shares can be proven.
+	for c := uint(1); c < eds.Width(); c++ {
+		eds.setCell(1, c, nil)
+	}
+	_, err := eds.ProveBadEncoding(&ErrByzantineData{Axis: Row, Index: 0, Shares: shares})
+	assert.Error(t, err)
+}
+
+func TestRepairAttachesBadEncodingProofs(t *testing.T) {
+	codec := NewLeoRSCodec()
+
+	t.Run("collected", func(t *testing.T) {
+		eds, rowRoots, colRoots := createByzantineEds(t, codec)
+		err := eds.Repair(rowRoots, colRoots, WithCollectByzantineData())
+		var byzAxes *ErrByzantineAxes
+		require.True(t, errors.As(err, &byzAxes))
+		require.NotEmpty(t, byzAxes.Errors)
+		for _, byzErr := range byzAxes.Errors {
+			require.NotNil(t, byzErr.Proof, "%s %d", byzErr.Axis, byzErr.Index)
+			assert.NoError(t, byzErr.Proof.Verify(rowRoots, colRoots, codec, NewDefaultTree))
+		}
+	})
+
+	t.Run("tree without proofs", func(t *testing.T) {
+		eds := createTestEds(codec, ShardSize)
+		eds.setCell(0, 0, bytes.Repeat([]byte{66}, ShardSize))
+		flattened := eds.Flattened()
+		byzantine, err := ImportExtendedDataSquare(flattened, codec, newUnprovableTree)
+		require.NoError(t, err)
+		rowRoots, err := byzantine.RowRoots()
+		require.NoError(t, err)
+		colRoots, err := byzantine.ColRoots()
+		require.NoError(t, err)
+
+		err = byzantine.Repair(rowRoots, colRoots)
+		var byzErr *ErrByzantineData
+		require.True(t, errors.As(err, &byzErr))
+		assert.Nil(t, byzErr.Proof)
+	})
+}
+
+// unprovableTree is a DefaultTree that does not implement ProvableTree.
+type unprovableTree struct {
+	Tree
+}
+
+func newUnprovableTree(axis Axis, index uint) Tree {
+	return unprovableTree{NewDefaultTree(axis, index)}
+}
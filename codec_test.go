@@ -1,9 +1,13 @@
 package rsmt2d
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -11,6 +15,55 @@ var (
 	decodedDataDump [][]byte
 )
 
+// testCodec wraps the Leopard codec under a different name so that it can be
+// registered alongside the codecs shipped with rsmt2d.
+type testCodec struct {
+	*LeoRSCodec
+}
+
+func (c testCodec) Name() string {
+	return "Test"
+}
+
+func TestRegisterCodec(t *testing.T) {
+	codec := testCodec{NewLeoRSCodec()}
+	require.NoError(t, RegisterCodec(codec.Name(), codec))
+	defer UnregisterCodec(codec.Name())
+
+	got, err := LookupCodec(codec.Name())
+	require.NoError(t, err)
+	assert.Equal(t, codec, got)
+	assert.Contains(t, RegisteredCodecs(), codec.Name())
+
+	t.Run("duplicate name returns an error", func(t *testing.T) {
+		assert.Error(t, RegisterCodec(codec.Name(), codec))
+	})
+	t.Run("nil codec returns an error", func(t *testing.T) {
+		assert.Error(t, RegisterCodec("Nil", nil))
+	})
+}
+
+func TestLookupCodec(t *testing.T) {
+	codec, err := LookupCodec(Leopard)
+	require.NoError(t, err)
+	assert.Equal(t, Leopard, codec.Name())
+
+	_, err = LookupCodec("Unknown")
+	var unknownErr *ErrUnknownCodec
+	require.True(t, errors.As(err, &unknownErr))
+	assert.Equal(t, "Unknown", unknownErr.Name)
+}
+
+func TestUnregisterCodec(t *testing.T) {
+	codec := testCodec{NewLeoRSCodec()}
+	require.NoError(t, RegisterCodec(codec.Name(), codec))
+	UnregisterCodec(codec.Name())
+
+	_, err := LookupCodec(codec.Name())
+	assert.Error(t, err)
+	assert.NotContains(t, RegisteredCodecs(), codec.Name())
+}
+
 func BenchmarkEncoding(b *testing.B) {
 	// generate some fake data
 	data := generateRandData(128)
@@ -89,3 +142,113 @@ func generateMissingData(count int, codec Codec) [][]byte {
 
 	return output
 }
+
+// TestCodecsRoundTrip verifies that every registered codec can reconstruct
+// the original data after half of the shares are lost, so that codecs can be
+// cross-checked against each other.
+func TestCodecsRoundTrip(t *testing.T) {
+	for _, name := range RegisteredCodecs() {
+		codec, err := LookupCodec(name)
+		require.NoError(t, err)
+
+		t.Run(name, func(t *testing.T) {
+			data := generateRandData(64)
+			parity, err := codec.Encode(data)
+			require.NoError(t, err)
+			require.Len(t, parity, len(data))
+
+			shares := make([][]byte, 0, len(data)*2)
+			shares = append(shares, data...)
+			shares = append(shares, parity...)
+			for i := 0; i < len(shares); i += 2 {
+				shares[i] = nil
+			}
+
+			decoded, err := codec.Decode(shares)
+			require.NoError(t, err)
+			assert.Equal(t, data, decoded[:len(data)])
+			assert.Equal(t, parity, decoded[len(data):])
+		})
+	}
+}
+
+func TestRSGF8CodecMaxShards(t *testing.T) {
+	codec := NewRSGF8Codec()
+	_, err := codec.Encode(generateRandData(rsgf8MaxShards/2 + 1))
+	assert.Error(t, err)
+}
+
+func TestCodecsEncodeInto(t *testing.T) {
+	for _, codec := range []Codec{NewLeoRSCodec(), NewRSGF8Codec(), NewLeoRSCodec(WithExtensionFactor(4))} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			enc, ok := codec.(EncodeIntoCodec)
+			require.True(t, ok)
+
+			data := generateRandData(64)[:16]
+			want, err := codec.Encode(data)
+			require.NoError(t, err)
+
+			parity := newParityShares(len(want), len(data[0]))
+			require.NoError(t, enc.EncodeInto(data, parity))
+			assert.Equal(t, want, parity)
+
+			assert.Error(t, enc.EncodeInto(data, parity[1:]))
+		})
+	}
+}
+
+func TestCodecsDecodeInto(t *testing.T) {
+	for _, codec := range []Codec{NewLeoRSCodec(), NewRSGF8Codec(), NewLeoRSCodec(WithExtensionFactor(4))} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			dec, ok := codec.(DecodeIntoCodec)
+			require.True(t, ok)
+
+			data := generateRandData(64)[:16]
+			parity, err := codec.Encode(data)
+			require.NoError(t, err)
+			want := append(append([][]byte(nil), data...), parity...)
+
+			shares := append([][]byte(nil), want...)
+			for i := 0; i < len(shares)-len(data); i++ {
+				shares[i] = nil
+			}
+			buffers := newParityShares(len(shares), len(data[0]))
+			// a missing share without a large enough buffer is allocated
+			buffers[1] = nil
+			require.NoError(t, dec.DecodeInto(shares, buffers))
+			assert.Equal(t, want, shares)
+			for i := 0; i < len(shares)-len(data); i++ {
+				if i != 1 {
+					assert.Same(t, &buffers[i][0], &shares[i][0])
+				}
+			}
+
+			assert.Error(t, dec.DecodeInto(make([][]byte, len(want)), buffers[1:]))
+			assert.Error(t, dec.DecodeInto(make([][]byte, len(want)), buffers))
+		})
+	}
+}
+
+func TestCodecsEncodeIntoAllocations(t *testing.T) {
+	for _, codec := range []Codec{NewLeoRSCodec(), NewRSGF8Codec()} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			enc := codec.(EncodeIntoCodec)
+			data := generateRandData(64)[:16]
+			parity := newParityShares(len(data), len(data[0]))
+			require.NoError(t, enc.EncodeInto(data, parity))
+
+			encodeAllocs := testing.AllocsPerRun(100, func() {
+				if _, err := enc.Encode(data); err != nil {
+					panic(err)
+				}
+			})
+			allocs := testing.AllocsPerRun(100, func() {
+				if err := enc.EncodeInto(data, parity); err != nil {
+					panic(err)
+				}
+			})
+			// the parity shares are not allocated
+			assert.Less(t, allocs, encodeAllocs)
+		})
+	}
+}
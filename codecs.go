@@ -1,6 +1,12 @@
 package rsmt2d
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/klauspost/reedsolomon"
+)
 
 const (
 	// Leopard is a codec that was originally implemented in the C++ library
@@ -9,8 +15,18 @@ const (
 	// uses 8-bit leopard for shards less than or equal to 256. The Leopard
 	// codec uses 16-bit leopard for shards greater than 256.
 	Leopard = "Leopard"
+	// RSGF8 is a classic Reed-Solomon codec over GF(2^8) using a systematic
+	// Vandermonde encoding matrix, as implemented in
+	// https://github.com/klauspost/reedsolomon. It supports at most 256 shards
+	// per row or column.
+	RSGF8 = "RSGF8"
 )
 
+// DefaultExtensionFactor is the factor by which codecs extend original data
+// unless configured otherwise: each row and column of the original data square
+// is extended with an equal number of parity shares.
+const DefaultExtensionFactor = 2
+
 type Codec interface {
 	// Encode encodes original data, automatically extracting share size.
 	// There must be no missing shares. Only returns parity shares.
@@ -24,12 +40,241 @@ type Codec interface {
 	Name() string
 }
 
-// codecs is a global map used for keeping track of registered codecs for testing and JSON unmarshalling
-var codecs = make(map[string]Codec)
+// ExtensionFactorCodec is an optional interface implemented by codecs that
+// extend original data by a factor other than DefaultExtensionFactor. A codec
+// with extension factor f encodes k original shares into (f-1)*k parity
+// shares and decodes f*k shares of which any k are present.
+type ExtensionFactorCodec interface {
+	Codec
+	// ExtensionFactor returns the ratio of extended to original shares.
+	ExtensionFactor() uint
+}
+
+// extensionFactor returns the extension factor of codec.
+func extensionFactor(codec Codec) uint {
+	if c, ok := codec.(ExtensionFactorCodec); ok {
+		return c.ExtensionFactor()
+	}
+	return DefaultExtensionFactor
+}
+
+// EncodeIntoCodec is an optional interface implemented by codecs that can
+// write parity shares into buffers provided by the caller. An
+// ExtendedDataSquare kept in a single buffer (see WithStorage) is extended in
+// place by such codecs, without allocating parity shares.
+type EncodeIntoCodec interface {
+	Codec
+	// EncodeInto encodes data like Encode, but writes the parity shares into
+	// parity, which must hold as many shares as Encode returns, each of the
+	// size of the data shares.
+	EncodeInto(data [][]byte, parity [][]byte) error
+}
+
+// DecodeIntoCodec is an optional interface implemented by codecs that can
+// reconstruct missing shares into buffers provided by the caller. An
+// ExtendedDataSquare kept in a single buffer (see WithStorage) is repaired in
+// place by such codecs, without allocating the repaired shares.
+type DecodeIntoCodec interface {
+	Codec
+	// DecodeInto decodes sparse original + parity data like Decode, but in
+	// place: every missing (nil) share data[i] is reconstructed into
+	// buffers[i] and data[i] is set to it. buffers must be as long as data,
+	// and buffers[i] must have the capacity of a share if data[i] is
+	// missing; otherwise a new share is allocated. If an error is returned,
+	// the contents of data and buffers are unspecified.
+	DecodeInto(data [][]byte, buffers [][]byte) error
+}
+
+// newParityShares allocates n parity shares of shareSize bytes in a single
+// buffer.
+func newParityShares(n int, shareSize int) [][]byte {
+	buf := make([]byte, n*shareSize)
+	parity := make([][]byte, n)
+	for i := range parity {
+		parity[i] = buf[i*shareSize : (i+1)*shareSize : (i+1)*shareSize]
+	}
+	return parity
+}
+
+// shardsPool holds the slices of shards passed to encoders by encodeShards.
+var shardsPool = sync.Pool{
+	New: func() any {
+		return new([][]byte)
+	},
+}
+
+// encodeShards encodes data with enc and writes the parity shares into
+// parity.
+func encodeShards(enc reedsolomon.Encoder, data [][]byte, parity [][]byte) error {
+	shards := shardsPool.Get().(*[][]byte)
+	defer func() {
+		for i := range *shards {
+			(*shards)[i] = nil
+		}
+		*shards = (*shards)[:0]
+		shardsPool.Put(shards)
+	}()
+	*shards = append(*shards, data...)
+	*shards = append(*shards, parity...)
+	return enc.Encode(*shards)
+}
+
+// reconstructInto reconstructs the missing shares of data with enc into
+// buffers, relying on enc to use the capacity of zero-length shards.
+func reconstructInto(enc reedsolomon.Encoder, data [][]byte, buffers [][]byte) error {
+	if len(buffers) != len(data) {
+		return fmt.Errorf("expected %d buffers, got %d", len(data), len(buffers))
+	}
+	for i := range data {
+		if data[i] == nil {
+			data[i] = buffers[i][:0]
+		}
+	}
+	return enc.Reconstruct(data)
+}
+
+// CodecOption configures a codec shipped with rsmt2d.
+type CodecOption func(*codecConfig)
+
+type codecConfig struct {
+	extensionFactor  uint
+	encoderCacheSize int
+}
+
+func newCodecConfig(opts []CodecOption) codecConfig {
+	cfg := codecConfig{
+		extensionFactor:  DefaultExtensionFactor,
+		encoderCacheSize: DefaultEncoderCacheSize,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithExtensionFactor sets the ratio of extended to original shares, e.g. 4
+// extends each row and column of k original shares to 4*k shares. Higher
+// factors lower the fraction of shares that are needed for reconstruction.
+// A factor of 0 selects DefaultExtensionFactor, and a codec configured with a
+// factor of 1 is rejected when used. Since squares extended with different
+// factors are not compatible, codecs with a non-default factor append it to
+// their name (e.g. "Leopard-4x") and must be registered under that name to be
+// resolved by LookupCodec.
+func WithExtensionFactor(factor uint) CodecOption {
+	return func(cfg *codecConfig) {
+		cfg.extensionFactor = factor
+	}
+}
+
+// factor returns the extension factor of cfg. The zero value of codecConfig,
+// e.g. of a LeoRSCodec{} literal, has the default extension factor.
+func (cfg codecConfig) factor() uint {
+	if cfg.extensionFactor == 0 {
+		return DefaultExtensionFactor
+	}
+	return cfg.extensionFactor
+}
+
+// name returns the name of a codec configured with cfg.
+func (cfg codecConfig) name(base string) string {
+	if cfg.factor() == DefaultExtensionFactor {
+		return base
+	}
+	return fmt.Sprintf("%s-%dx", base, cfg.factor())
+}
+
+// shardCounts returns the number of data and parity shards of a codeword with
+// total shards, or an error if total is not a multiple of the extension
+// factor.
+func (cfg codecConfig) shardCounts(total int) (int, int, error) {
+	factor := cfg.factor()
+	if factor < DefaultExtensionFactor {
+		return 0, 0, fmt.Errorf("extension factor must be at least %d, got %d", DefaultExtensionFactor, factor)
+	}
+	if total%int(factor) != 0 {
+		return 0, 0, fmt.Errorf("number of shares %d is not a multiple of the extension factor %d", total, factor)
+	}
+	dataLen := total / int(factor)
+	return dataLen, total - dataLen, nil
+}
+
+// ErrUnknownCodec is returned when a codec is looked up by a name that has not
+// been registered.
+type ErrUnknownCodec struct {
+	// Name is the name of the codec that was looked up.
+	Name string
+}
+
+func (e *ErrUnknownCodec) Error() string {
+	return fmt.Sprintf("unknown codec: %q", e.Name)
+}
+
+var (
+	// codecsMutex guards codecs.
+	codecsMutex sync.RWMutex
+	// codecs is a global map used for keeping track of registered codecs for testing and JSON unmarshalling
+	codecs = make(map[string]Codec)
+)
+
+// RegisterCodec makes a codec available under the provided name, so that it
+// can be resolved by LookupCodec (e.g. when unmarshalling an
+// ExtendedDataSquare). Returns an error if the codec is nil or if a codec is
+// already registered under the same name.
+func RegisterCodec(name string, codec Codec) error {
+	if codec == nil {
+		return fmt.Errorf("cannot register nil codec %q", name)
+	}
+
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+
+	if codecs[name] != nil {
+		return fmt.Errorf("codec %q already registered", name)
+	}
+	codecs[name] = codec
+	return nil
+}
+
+// UnregisterCodec removes the codec registered under the provided name. It is
+// a no-op if no such codec exists. It is intended to be used by tests that
+// register codecs temporarily.
+func UnregisterCodec(name string) {
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+
+	delete(codecs, name)
+}
+
+// LookupCodec returns the codec registered under the provided name. Returns an
+// *ErrUnknownCodec if no such codec exists.
+func LookupCodec(name string) (Codec, error) {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, &ErrUnknownCodec{Name: name}
+	}
+	return codec, nil
+}
+
+// RegisteredCodecs returns the names of all registered codecs in sorted order.
+func RegisteredCodecs() []string {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
+// registerCodec registers a codec that ships with rsmt2d. It panics if the
+// codec cannot be registered.
 func registerCodec(ct string, codec Codec) {
-	if codecs[ct] != nil {
-		panic(fmt.Sprintf("%v already registered", codec))
+	if err := RegisterCodec(ct, codec); err != nil {
+		panic(err)
 	}
-	codecs[ct] = codec
 }
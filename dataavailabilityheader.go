@@ -0,0 +1,178 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/minio/sha256-simd"
+
+	"github.com/celestiaorg/merkletree"
+)
+
+// dataAvailabilityHeaderVersion is the version of the binary serialization of
+// a DataAvailabilityHeader.
+const dataAvailabilityHeaderVersion = 1
+
+// ErrInvalidDataAvailabilityHeader is returned when a DataAvailabilityHeader
+// cannot be the header of an extended data square.
+var ErrInvalidDataAvailabilityHeader = errors.New("invalid data availability header")
+
+// DataAvailabilityHeader commits to an extended data square with the Merkle
+// roots of its rows and columns.
+type DataAvailabilityHeader struct {
+	RowRoots [][]byte `json:"row_roots"`
+	ColRoots [][]byte `json:"column_roots"`
+}
+
+// NewDataAvailabilityHeader returns the DataAvailabilityHeader of eds.
+func NewDataAvailabilityHeader(eds *ExtendedDataSquare) (DataAvailabilityHeader, error) {
+	rowRoots, err := eds.RowRoots()
+	if err != nil {
+		return DataAvailabilityHeader{}, err
+	}
+	colRoots, err := eds.ColRoots()
+	if err != nil {
+		return DataAvailabilityHeader{}, err
+	}
+	return DataAvailabilityHeader{RowRoots: rowRoots, ColRoots: colRoots}, nil
+}
+
+// Hash returns the data root, i.e. the root of a SHA-256 Merkle tree whose
+// leaves are the row roots followed by the column roots. It uses SHA-256
+// whatever the hash function of the trees of the square, so for squares whose
+// trees hash with another function, e.g. those created by
+// NewDefaultTreeWithHasher, use HashWithTree instead.
+func (dah *DataAvailabilityHeader) Hash() []byte {
+	return dah.HashWith(sha256.New())
+}
+
+// HashWithTree is like Hash, but uses the hash function of the trees created
+// by treeCreatorFn if they implement HasherTree, and SHA-256 otherwise. It
+// must be given the TreeConstructorFn the square was created with.
+func (dah *DataAvailabilityHeader) HashWithTree(treeCreatorFn TreeConstructorFn) []byte {
+	if tree, ok := treeCreatorFn(Row, 0).(HasherTree); ok {
+		return dah.HashWith(tree.NewHash())
+	}
+	return dah.Hash()
+}
+
+// HashWith is like Hash, but uses h as the hash function of the Merkle tree.
+// h should be the hash function of the trees of the square, see HashWithTree.
+func (dah *DataAvailabilityHeader) HashWith(h hash.Hash) []byte {
+	tree := merkletree.New(h)
+	for _, root := range dah.RowRoots {
+		tree.Push(root)
+	}
+	for _, root := range dah.ColRoots {
+		tree.Push(root)
+	}
+	return tree.Root()
+}
+
+// ValidateBasic checks that the header has enough row and column roots for
+// an extended data square and that all roots are of the same, non-zero size.
+func (dah *DataAvailabilityHeader) ValidateBasic() error {
+	if len(dah.RowRoots) < int(DefaultExtensionFactor) || len(dah.ColRoots) < int(DefaultExtensionFactor) {
+		return fmt.Errorf("%w: expected at least %d row and column roots, got %d and %d",
+			ErrInvalidDataAvailabilityHeader, DefaultExtensionFactor, len(dah.RowRoots), len(dah.ColRoots))
+	}
+	size := len(dah.RowRoots[0])
+	if size == 0 {
+		return fmt.Errorf("%w: empty row root 0", ErrInvalidDataAvailabilityHeader)
+	}
+	for i, root := range dah.RowRoots {
+		if len(root) != size {
+			return fmt.Errorf("%w: row root %d has size %d, expected %d", ErrInvalidDataAvailabilityHeader, i, len(root), size)
+		}
+	}
+	for i, root := range dah.ColRoots {
+		if len(root) != size {
+			return fmt.Errorf("%w: col root %d has size %d, expected %d", ErrInvalidDataAvailabilityHeader, i, len(root), size)
+		}
+	}
+	return nil
+}
+
+// Equals returns true if both headers contain the same roots.
+func (dah *DataAvailabilityHeader) Equals(other *DataAvailabilityHeader) bool {
+	return equalRoots(dah.RowRoots, other.RowRoots) && equalRoots(dah.ColRoots, other.ColRoots)
+}
+
+func equalRoots(a [][]byte, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary encodes the header in a compact binary format.
+func (dah *DataAvailabilityHeader) MarshalBinary() ([]byte, error) {
+	buf := []byte{dataAvailabilityHeaderVersion}
+	for _, roots := range [][][]byte{dah.RowRoots, dah.ColRoots} {
+		buf = appendUvarint(buf, uint64(len(roots)))
+		for _, root := range roots {
+			buf = appendBytes(buf, root)
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a header encoded with MarshalBinary.
+func (dah *DataAvailabilityHeader) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	version := make([]byte, 1)
+	if _, err := io.ReadFull(r, version); err != nil {
+		return err
+	}
+	if version[0] != dataAvailabilityHeaderVersion {
+		return fmt.Errorf("unsupported data availability header version %d", version[0])
+	}
+
+	var decoded [2][][]byte
+	for i := range decoded {
+		length, err := readUvarint(r, uint64(r.Len()))
+		if err != nil {
+			return err
+		}
+		decoded[i] = make([][]byte, length)
+		for j := range decoded[i] {
+			if decoded[i][j], err = readBytes(r); err != nil {
+				return err
+			}
+		}
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%d trailing bytes after data availability header", r.Len())
+	}
+
+	dah.RowRoots, dah.ColRoots = decoded[0], decoded[1]
+	return nil
+}
+
+// RepairWithHeader is like RepairWithContext, using the roots of dah. The
+// header is validated with ValidateBasic first.
+func (eds *ExtendedDataSquare) RepairWithHeader(ctx context.Context, dah *DataAvailabilityHeader, opts ...Option) error {
+	if err := dah.ValidateBasic(); err != nil {
+		return err
+	}
+	return eds.RepairWithContext(ctx, dah.RowRoots, dah.ColRoots, opts...)
+}
+
+// VerifyWithHeader is like Verify, using the roots of dah. The header is
+// validated with ValidateBasic first.
+func (eds *ExtendedDataSquare) VerifyWithHeader(dah *DataAvailabilityHeader, opts ...Option) error {
+	if err := dah.ValidateBasic(); err != nil {
+		return err
+	}
+	return eds.Verify(dah.RowRoots, dah.ColRoots, opts...)
+}
@@ -0,0 +1,123 @@
+package rsmt2d
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/json"
+	"testing"
+
+	"github.com/minio/sha256-simd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/celestiaorg/merkletree"
+)
+
+func TestNewDataAvailabilityHeader(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	dah, err := NewDataAvailabilityHeader(eds)
+	require.NoError(t, err)
+	assert.Equal(t, rowRoots, dah.RowRoots)
+	assert.Equal(t, colRoots, dah.ColRoots)
+	assert.NoError(t, dah.ValidateBasic())
+
+	tree := merkletree.New(sha256.New())
+	for _, root := range append(rowRoots, colRoots...) {
+		tree.Push(root)
+	}
+	assert.Equal(t, tree.Root(), dah.Hash())
+	assert.Len(t, dah.HashWith(sha512.New()), sha512.Size)
+
+	// the hash commits to the roots and their order
+	swapped := DataAvailabilityHeader{RowRoots: colRoots, ColRoots: rowRoots}
+	assert.NotEqual(t, dah.Hash(), swapped.Hash())
+
+	// the data root uses the hash function of the trees
+	assert.Equal(t, dah.Hash(), dah.HashWithTree(NewDefaultTree))
+	assert.Equal(t, dah.Hash(), dah.HashWithTree(newUnprovableTree))
+	assert.Equal(t, dah.HashWith(sha512.New512_256()), dah.HashWithTree(NewDefaultTreeWithHasher(sha512.New512_256)))
+	assert.Equal(t, dah.HashWith(sha512.New()), dah.HashWithTree(NewDefaultTreeWithOptions(WithTreeHasher(sha512.New), WithDomainTags())))
+}
+
+func TestDataAvailabilityHeaderValidateBasic(t *testing.T) {
+	root := make([]byte, 32)
+	tests := []struct {
+		name    string
+		dah     DataAvailabilityHeader
+		wantErr bool
+	}{
+		{"valid", DataAvailabilityHeader{[][]byte{root, root}, [][]byte{root, root}}, false},
+		{"rectangle", DataAvailabilityHeader{[][]byte{root, root}, [][]byte{root, root, root, root}}, false},
+		{"empty", DataAvailabilityHeader{}, true},
+		{"too few col roots", DataAvailabilityHeader{[][]byte{root, root}, [][]byte{root}}, true},
+		{"empty root", DataAvailabilityHeader{[][]byte{{}, {}}, [][]byte{{}, {}}}, true},
+		{"uneven roots", DataAvailabilityHeader{[][]byte{root, root}, [][]byte{root, root[1:]}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dah.ValidateBasic()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDataAvailabilityHeader)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDataAvailabilityHeaderSerialization(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	dah, err := NewDataAvailabilityHeader(eds)
+	require.NoError(t, err)
+
+	t.Run("binary", func(t *testing.T) {
+		data, err := dah.MarshalBinary()
+		require.NoError(t, err)
+		var decoded DataAvailabilityHeader
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.True(t, dah.Equals(&decoded))
+
+		assert.Error(t, decoded.UnmarshalBinary(data[:len(data)-1]))
+		assert.Error(t, decoded.UnmarshalBinary(append(data, 0)))
+		data[0] = 0
+		assert.Error(t, decoded.UnmarshalBinary(data))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(&dah)
+		require.NoError(t, err)
+		var decoded DataAvailabilityHeader
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.True(t, dah.Equals(&decoded))
+		assert.Equal(t, dah.Hash(), decoded.Hash())
+	})
+}
+
+func TestRepairWithHeader(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	require.NoError(t, err)
+	dah, err := NewDataAvailabilityHeader(original)
+	require.NoError(t, err)
+	assert.NoError(t, original.VerifyWithHeader(&dah))
+
+	flattened := original.Flattened()
+	for i := 0; i < len(flattened); i += 2 {
+		flattened[i] = nil
+	}
+	eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+	require.NoError(t, err)
+	require.NoError(t, eds.RepairWithHeader(context.Background(), &dah))
+	assert.Equal(t, original.Flattened(), eds.Flattened())
+
+	invalid := DataAvailabilityHeader{RowRoots: dah.RowRoots}
+	assert.ErrorIs(t, eds.RepairWithHeader(context.Background(), &invalid), ErrInvalidDataAvailabilityHeader)
+	assert.ErrorIs(t, eds.VerifyWithHeader(&invalid), ErrInvalidDataAvailabilityHeader)
+}
@@ -1,12 +1,11 @@
 package rsmt2d
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
 	"sync"
-
-	"golang.org/x/sync/errgroup"
 )
 
 // ErrUnevenChunks is thrown when non-nil chunks are not all of equal size.
@@ -14,16 +13,24 @@ var ErrUnevenChunks = errors.New("non-nil chunks not all of equal size")
 
 // dataSquare stores all data for an original data square (ODS) or extended
 // data square (EDS). Data is duplicated in both row-major and column-major
-// order in order to be able to provide zero-allocation column slices.
+// order in order to be able to provide zero-allocation column slices. Despite
+// its name, a dataSquare may also be a rectangle with a different number of
+// rows and columns.
 type dataSquare struct {
 	squareRow    [][][]byte // row-major
 	squareCol    [][][]byte // col-major
 	dataMutex    sync.Mutex
-	width        uint
+	width        uint // number of columns, i.e. the length of a row
+	height       uint // number of rows, i.e. the length of a column
 	chunkSize    uint
 	rowRoots     [][]byte
 	colRoots     [][]byte
 	createTreeFn TreeConstructorFn
+	opts         options
+	// buf holds the shares in row-major order, with rows of bufWidth shares,
+	// if the square is backed by a Storage. It is nil otherwise.
+	buf      []byte
+	bufWidth uint
 }
 
 func newDataSquare(data [][]byte, treeCreator TreeConstructorFn) (*dataSquare, error) {
@@ -32,6 +39,23 @@ func newDataSquare(data [][]byte, treeCreator TreeConstructorFn) (*dataSquare, e
 		return nil, errors.New("number of chunks must be a square number")
 	}
 
+	return newDataRectangle(data, uint(width), treeCreator)
+}
+
+// newDataRectangle creates a dataSquare with height rows from data in
+// row-major order.
+func newDataRectangle(data [][]byte, height uint, treeCreator TreeConstructorFn) (*dataSquare, error) {
+	if height == 0 {
+		if len(data) != 0 {
+			return nil, errors.New("number of rows must be positive")
+		}
+		return &dataSquare{createTreeFn: treeCreator}, nil
+	}
+	if uint(len(data))%height != 0 {
+		return nil, fmt.Errorf("number of chunks %d must be a multiple of the number of rows %d", len(data), height)
+	}
+	width := uint(len(data)) / height
+
 	var chunkSize int
 	for _, d := range data {
 		if d != nil {
@@ -43,11 +67,11 @@ func newDataSquare(data [][]byte, treeCreator TreeConstructorFn) (*dataSquare, e
 		}
 	}
 
-	squareRow := make([][][]byte, width)
-	for i := 0; i < width; i++ {
+	squareRow := make([][][]byte, height)
+	for i := uint(0); i < height; i++ {
 		squareRow[i] = data[i*width : i*width+width]
 
-		for j := 0; j < width; j++ {
+		for j := uint(0); j < width; j++ {
 			if squareRow[i][j] != nil && len(squareRow[i][j]) != chunkSize {
 				return nil, ErrUnevenChunks
 			}
@@ -55,9 +79,10 @@ func newDataSquare(data [][]byte, treeCreator TreeConstructorFn) (*dataSquare, e
 	}
 
 	squareCol := make([][][]byte, width)
-	for j := 0; j < width; j++ {
-		squareCol[j] = make([][]byte, width)
-		for i := 0; i < width; i++ {
+	cols := make([][]byte, width*height)
+	for j := uint(0); j < width; j++ {
+		squareCol[j] = cols[j*height : (j+1)*height : (j+1)*height]
+		for i := uint(0); i < height; i++ {
 			squareCol[j][i] = data[i*width+j]
 		}
 	}
@@ -65,7 +90,8 @@ func newDataSquare(data [][]byte, treeCreator TreeConstructorFn) (*dataSquare, e
 	return &dataSquare{
 		squareRow:    squareRow,
 		squareCol:    squareCol,
-		width:        uint(width),
+		width:        width,
+		height:       height,
 		chunkSize:    uint(chunkSize),
 		createTreeFn: treeCreator,
 	}, nil
@@ -74,45 +100,60 @@ func newDataSquare(data [][]byte, treeCreator TreeConstructorFn) (*dataSquare, e
 // extendSquare extends the original data square by extendedWidth and fills
 // the extended quadrants with fillerChunk.
 func (ds *dataSquare) extendSquare(extendedWidth uint, fillerChunk []byte) error {
+	return ds.extendRectangle(extendedWidth, extendedWidth, fillerChunk)
+}
+
+// extendRectangle extends the original data by extendedHeight rows and
+// extendedWidth columns and fills the extended quadrants with fillerChunk.
+func (ds *dataSquare) extendRectangle(extendedHeight uint, extendedWidth uint, fillerChunk []byte) error {
 	if uint(len(fillerChunk)) != ds.chunkSize {
 		return errors.New("filler chunk size does not match data square chunk size")
 	}
 
 	newWidth := ds.width + extendedWidth
-	newSquareRow := make([][][]byte, newWidth)
-
-	fillerExtendedRow := make([][]byte, extendedWidth)
-	for i := uint(0); i < extendedWidth; i++ {
-		fillerExtendedRow[i] = fillerChunk
-	}
+	newHeight := ds.height + extendedHeight
 
 	fillerRow := make([][]byte, newWidth)
 	for i := uint(0); i < newWidth; i++ {
 		fillerRow[i] = fillerChunk
 	}
 
-	row := make([][]byte, ds.width)
-	for i := uint(0); i < ds.width; i++ {
-		copy(row, ds.squareRow[i])
-		newSquareRow[i] = append(row, fillerExtendedRow...)
+	// the rows and columns are slices of a single array each, to avoid an
+	// allocation per row and column
+	newSquareRow := make([][][]byte, newHeight)
+	rows := make([][]byte, newWidth*newHeight)
+	for i := uint(0); i < newHeight; i++ {
+		row := rows[i*newWidth : (i+1)*newWidth : (i+1)*newWidth]
+		copy(row, fillerRow)
+		if i < ds.height {
+			copy(row, ds.squareRow[i])
+		}
+		newSquareRow[i] = row
 	}
 
-	for i := ds.width; i < newWidth; i++ {
-		newSquareRow[i] = make([][]byte, newWidth)
-		copy(newSquareRow[i], fillerRow)
+	if ds.buf != nil {
+		for i := uint(0); i < newHeight; i++ {
+			for j := uint(0); j < newWidth; j++ {
+				if i >= ds.height || j >= ds.width {
+					newSquareRow[i][j] = ds.store(i, j, fillerChunk)
+				}
+			}
+		}
 	}
 
 	ds.squareRow = newSquareRow
 
 	newSquareCol := make([][][]byte, newWidth)
+	cols := make([][]byte, newWidth*newHeight)
 	for j := uint(0); j < newWidth; j++ {
-		newSquareCol[j] = make([][]byte, newWidth)
-		for i := uint(0); i < newWidth; i++ {
+		newSquareCol[j] = cols[j*newHeight : (j+1)*newHeight : (j+1)*newHeight]
+		for i := uint(0); i < newHeight; i++ {
 			newSquareCol[j][i] = newSquareRow[i][j]
 		}
 	}
 	ds.squareCol = newSquareCol
 	ds.width = newWidth
+	ds.height = newHeight
 
 	ds.resetRoots()
 
@@ -143,8 +184,9 @@ func (ds *dataSquare) setRowSlice(x uint, y uint, newRow [][]byte) error {
 	defer ds.dataMutex.Unlock()
 
 	for i := uint(0); i < uint(len(newRow)); i++ {
-		ds.squareRow[x][y+i] = newRow[i]
-		ds.squareCol[y+i][x] = newRow[i]
+		chunk := ds.store(x, y+i, newRow[i])
+		ds.squareRow[x][y+i] = chunk
+		ds.squareCol[y+i][x] = chunk
 	}
 
 	ds.resetRoots()
@@ -159,7 +201,24 @@ func (ds *dataSquare) colSlice(x uint, y uint, length uint) [][]byte {
 // col returns a column slice.
 // Do not modify this slice directly, instead use SetCell.
 func (ds *dataSquare) col(y uint) [][]byte {
-	return ds.colSlice(0, y, ds.width)
+	return ds.colSlice(0, y, ds.height)
+}
+
+// axis returns the row or column at index.
+// Do not modify this slice directly, instead use SetCell.
+func (ds *dataSquare) axis(axis Axis, index uint) [][]byte {
+	if axis == Row {
+		return ds.row(index)
+	}
+	return ds.col(index)
+}
+
+// axisLen returns the number of rows or columns.
+func (ds *dataSquare) axisLen(axis Axis) uint {
+	if axis == Row {
+		return ds.height
+	}
+	return ds.width
 }
 
 func (ds *dataSquare) setColSlice(x uint, y uint, newCol [][]byte) error {
@@ -168,16 +227,17 @@ func (ds *dataSquare) setColSlice(x uint, y uint, newCol [][]byte) error {
 			return errors.New("invalid chunk size")
 		}
 	}
-	if x+uint(len(newCol)) > ds.width {
-		return fmt.Errorf("cannot set col slice at (%d, %d) of length %d: because it would exceed the data square width %d", x, y, len(newCol), ds.width)
+	if x+uint(len(newCol)) > ds.height {
+		return fmt.Errorf("cannot set col slice at (%d, %d) of length %d: because it would exceed the data square height %d", x, y, len(newCol), ds.height)
 	}
 
 	ds.dataMutex.Lock()
 	defer ds.dataMutex.Unlock()
 
 	for i := uint(0); i < uint(len(newCol)); i++ {
-		ds.squareRow[x+i][y] = newCol[i]
-		ds.squareCol[y][x+i] = newCol[i]
+		chunk := ds.store(x+i, y, newCol[i])
+		ds.squareRow[x+i][y] = chunk
+		ds.squareCol[y][x+i] = chunk
 	}
 
 	ds.resetRoots()
@@ -198,12 +258,12 @@ func (ds *dataSquare) resetRoots() {
 }
 
 func (ds *dataSquare) computeRoots() error {
-	var g errgroup.Group
+	g, _ := newWorkGroup(context.Background(), ds.opts)
 
-	rowRoots := make([][]byte, ds.width)
+	rowRoots := make([][]byte, ds.height)
 	colRoots := make([][]byte, ds.width)
 
-	for i := uint(0); i < ds.width; i++ {
+	for i := uint(0); i < ds.height; i++ {
 		i := i // https://go.dev/doc/faq#closures_and_goroutines
 		g.Go(func() error {
 			rowRoot, err := ds.getRowRoot(i)
@@ -213,7 +273,10 @@ func (ds *dataSquare) computeRoots() error {
 			rowRoots[i] = rowRoot
 			return nil
 		})
+	}
 
+	for i := uint(0); i < ds.width; i++ {
+		i := i // https://go.dev/doc/faq#closures_and_goroutines
 		g.Go(func() error {
 			colRoot, err := ds.getColRoot(i)
 			if err != nil {
@@ -313,12 +376,80 @@ func (ds *dataSquare) SetCell(x uint, y uint, newChunk []byte) error {
 	if len(newChunk) != int(ds.chunkSize) {
 		return fmt.Errorf("cannot set cell with chunk size %d because dataSquare chunk size is %d", len(newChunk), ds.chunkSize)
 	}
+	if ds.buf != nil && len(newChunk) == 0 {
+		return errEmptyStoredShare
+	}
+	if ds.buf != nil && uint(len(ds.buf)) < ds.bufWidth*ds.height*ds.chunkSize {
+		return fmt.Errorf("cannot set cell with chunk size %d because the storage of the data square is too small", len(newChunk))
+	}
+	newChunk = ds.store(x, y, newChunk)
 	ds.squareRow[x][y] = newChunk
 	ds.squareCol[y][x] = newChunk
 	ds.resetRoots()
 	return nil
 }
 
+// useBuffer moves the shares of the square into buf, in which the shares are
+// laid out in row-major order with rows of width shares. Shares set later are
+// copied into buf as well.
+func (ds *dataSquare) useBuffer(buf []byte, width uint, height uint) error {
+	if size := width * height * ds.chunkSize; uint(len(buf)) < size {
+		return fmt.Errorf("storage of %d bytes is too small for %dx%d shares of %d bytes", len(buf), height, width, ds.chunkSize)
+	}
+	ds.buf = buf
+	ds.bufWidth = width
+	for x, row := range ds.squareRow {
+		for y, chunk := range row {
+			chunk = ds.store(uint(x), uint(y), chunk)
+			ds.squareRow[x][y] = chunk
+			ds.squareCol[y][x] = chunk
+		}
+	}
+	return nil
+}
+
+// bufferChunk returns the slice of the buffer of the square that holds the
+// chunk at (x, y).
+func (ds *dataSquare) bufferChunk(x uint, y uint) []byte {
+	offset := (x*ds.bufWidth + y) * ds.chunkSize
+	return ds.buf[offset : offset+ds.chunkSize : offset+ds.chunkSize]
+}
+
+// axisBuffers returns the slices of the buffer of the square that hold the
+// chunks of the row or column at index.
+func (ds *dataSquare) axisBuffers(axis Axis, index uint) [][]byte {
+	var buffers [][]byte
+	switch axis {
+	case Row:
+		buffers = make([][]byte, ds.width)
+		for y := range buffers {
+			buffers[y] = ds.bufferChunk(index, uint(y))
+		}
+	case Col:
+		buffers = make([][]byte, ds.height)
+		for x := range buffers {
+			buffers[x] = ds.bufferChunk(uint(x), index)
+		}
+	}
+	return buffers
+}
+
+// store returns the chunk to keep at (x, y) for newChunk: a slice of the
+// buffer of the square holding a copy of newChunk, or newChunk itself if the
+// square has no buffer or newChunk is nil.
+func (ds *dataSquare) store(x uint, y uint, newChunk []byte) []byte {
+	if ds.buf == nil || newChunk == nil {
+		return newChunk
+	}
+	chunk := ds.bufferChunk(x, y)
+	// newChunk may already be stored at (x, y), e.g. when a square is
+	// imported from its own storage
+	if len(chunk) > 0 && &chunk[0] != &newChunk[0] {
+		copy(chunk, newChunk)
+	}
+	return chunk
+}
+
 // Flattened returns the concatenated rows of the data square.
 func (ds *dataSquare) Flattened() [][]byte {
 	flattened := [][]byte(nil)
@@ -33,6 +33,18 @@ func TestNewDataSquare(t *testing.T) {
 	}
 }
 
+func TestNewDataRectangle(t *testing.T) {
+	result, err := newDataRectangle([][]byte{{1, 2}, {3, 4}, {5, 6}, {7, 8}, {9, 10}, {11, 12}}, 2, NewDefaultTree)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), result.width)
+	assert.Equal(t, uint(2), result.height)
+	assert.Equal(t, [][][]byte{{{1, 2}, {3, 4}, {5, 6}}, {{7, 8}, {9, 10}, {11, 12}}}, result.squareRow)
+	assert.Equal(t, [][]byte{{3, 4}, {9, 10}}, result.col(1))
+
+	_, err = newDataRectangle([][]byte{{1, 2}, {3, 4}, {5, 6}}, 2, NewDefaultTree)
+	assert.Error(t, err)
+}
+
 func TestInvalidDataSquareCreation(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -175,6 +187,20 @@ func TestExtendSquare(t *testing.T) {
 	}
 }
 
+func TestExtendRectangle(t *testing.T) {
+	ds, err := newDataRectangle([][]byte{{1}, {2}}, 1, NewDefaultTree)
+	assert.NoError(t, err)
+	err = ds.extendRectangle(2, 1, []byte{0})
+	assert.NoError(t, err)
+	assert.Equal(t, [][][]byte{{{1}, {2}, {0}}, {{0}, {0}, {0}}, {{0}, {0}, {0}}}, ds.squareRow)
+	assert.Equal(t, uint(3), ds.width)
+	assert.Equal(t, uint(3), ds.height)
+
+	rowRoots, err := ds.getRowRoots()
+	assert.NoError(t, err)
+	assert.Len(t, rowRoots, 3)
+}
+
 func TestInvalidSquareExtension(t *testing.T) {
 	ds, err := newDataSquare([][]byte{{1, 2}}, NewDefaultTree)
 	if err != nil {
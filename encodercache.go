@@ -0,0 +1,122 @@
+package rsmt2d
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// DefaultEncoderCacheSize is the number of encoders cached by the codecs
+// shipped with rsmt2d unless configured otherwise with WithEncoderCacheSize.
+const DefaultEncoderCacheSize = 32
+
+// WithEncoderCacheSize sets the maximum number of encoders cached by a codec
+// shipped with rsmt2d. A codec needs one encoder per width of original data,
+// which is costly to create. Once the cache is full, the least recently used
+// encoder is evicted. Sizes below 1 are treated as 1.
+func WithEncoderCacheSize(size int) CodecOption {
+	return func(cfg *codecConfig) {
+		if size < 1 {
+			size = 1
+		}
+		cfg.encoderCacheSize = size
+	}
+}
+
+// EncoderCacheStats describes the usage of the encoder cache of a codec.
+type EncoderCacheStats struct {
+	// Hits is the number of times an encoder was found in the cache.
+	Hits uint64
+	// Misses is the number of times an encoder was created, including by
+	// pre-warming.
+	Misses uint64
+	// Evictions is the number of encoders that were evicted from the cache.
+	Evictions uint64
+	// Size is the number of cached encoders.
+	Size int
+	// Capacity is the maximum number of cached encoders.
+	Capacity int
+}
+
+// encoderCache is a least recently used cache of encoders by number of data
+// shards. The zero value is an empty cache of DefaultEncoderCacheSize
+// encoders.
+type encoderCache struct {
+	mu       sync.Mutex
+	capacity int
+	// lru holds the cached *encoderCacheEntry values, most recently used
+	// first.
+	lru     *list.List
+	entries map[int]*list.Element
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type encoderCacheEntry struct {
+	dataLen int
+	enc     reedsolomon.Encoder
+}
+
+// init allocates the cache on first use. It must be called with mu held.
+func (c *encoderCache) init() {
+	if c.lru == nil {
+		c.lru = list.New()
+		c.entries = make(map[int]*list.Element)
+	}
+	if c.capacity < 1 {
+		c.capacity = DefaultEncoderCacheSize
+	}
+}
+
+// get returns the encoder for dataLen data shards, creating it with
+// newEncoder if it is not cached. The encoder is created without holding the
+// lock, so that creating an encoder does not block lookups of other encoders.
+func (c *encoderCache) get(dataLen int, newEncoder func() (reedsolomon.Encoder, error)) (reedsolomon.Encoder, error) {
+	c.mu.Lock()
+	c.init()
+	if elem, ok := c.entries[dataLen]; ok {
+		c.hits++
+		c.lru.MoveToFront(elem)
+		c.mu.Unlock()
+		return elem.Value.(*encoderCacheEntry).enc, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	enc, err := newEncoder()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// another goroutine may have created the same encoder in the meantime
+	if elem, ok := c.entries[dataLen]; ok {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*encoderCacheEntry).enc, nil
+	}
+	c.entries[dataLen] = c.lru.PushFront(&encoderCacheEntry{dataLen, enc})
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*encoderCacheEntry).dataLen)
+		c.evictions++
+	}
+	return enc, nil
+}
+
+func (c *encoderCache) stats() EncoderCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	return EncoderCacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Size:      c.lru.Len(),
+		Capacity:  c.capacity,
+	}
+}
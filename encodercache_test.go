@@ -0,0 +1,111 @@
+package rsmt2d
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/reedsolomon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoderCache(t *testing.T) {
+	cache := &encoderCache{capacity: 2}
+	created := 0
+	get := func(dataLen int) reedsolomon.Encoder {
+		enc, err := cache.get(dataLen, func() (reedsolomon.Encoder, error) {
+			created++
+			return reedsolomon.New(dataLen, dataLen)
+		})
+		require.NoError(t, err)
+		return enc
+	}
+
+	first := get(1)
+	get(2)
+	assert.Same(t, first, get(1))
+	// 2 is the least recently used encoder
+	get(3)
+	get(1)
+	get(2)
+	assert.Equal(t, 4, created)
+	assert.Equal(t, EncoderCacheStats{Hits: 2, Misses: 4, Evictions: 2, Size: 2, Capacity: 2}, cache.stats())
+
+	// failed creations are not cached
+	_, err := cache.get(4, func() (reedsolomon.Encoder, error) {
+		return nil, errors.New("failed")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 2, cache.stats().Size)
+}
+
+func TestEncoderCacheConcurrent(t *testing.T) {
+	cache := &encoderCache{capacity: 4}
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		dataLen := i%8 + 1
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			enc, err := cache.get(dataLen, func() (reedsolomon.Encoder, error) {
+				return reedsolomon.New(dataLen, dataLen)
+			})
+			assert.NoError(t, err)
+			assert.NotNil(t, enc)
+		}()
+	}
+	wg.Wait()
+
+	stats := cache.stats()
+	assert.Equal(t, uint64(64), stats.Hits+stats.Misses)
+	assert.Equal(t, 4, stats.Size)
+}
+
+func TestCodecEncoderCache(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec interface {
+			Codec
+			PrewarmEncoders(widths ...uint) error
+			EncoderCacheStats() EncoderCacheStats
+		}
+	}{
+		{"leopard", NewLeoRSCodec(WithEncoderCacheSize(2))},
+		{"rsgf8", NewRSGF8Codec(WithEncoderCacheSize(2))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec := tt.codec
+			require.NoError(t, codec.PrewarmEncoders(2, 4))
+			assert.Equal(t, EncoderCacheStats{Misses: 2, Size: 2, Capacity: 2}, codec.EncoderCacheStats())
+
+			_, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+			require.NoError(t, err)
+			stats := codec.EncoderCacheStats()
+			assert.Equal(t, uint64(2), stats.Misses)
+			assert.Positive(t, stats.Hits)
+
+			_, err = ComputeExtendedDataSquare(genRandDS(8), codec, NewDefaultTree)
+			require.NoError(t, err)
+			stats = codec.EncoderCacheStats()
+			assert.Equal(t, uint64(3), stats.Misses)
+			assert.Equal(t, uint64(1), stats.Evictions)
+			assert.Equal(t, 2, stats.Size)
+		})
+	}
+
+	assert.Error(t, NewRSGF8Codec().PrewarmEncoders(rsgf8MaxShards))
+	assert.Equal(t, DefaultEncoderCacheSize, NewLeoRSCodec().EncoderCacheStats().Capacity)
+	assert.Equal(t, 1, NewLeoRSCodec(WithEncoderCacheSize(0)).EncoderCacheStats().Capacity)
+}
+
+func TestEncoderCacheZeroValue(t *testing.T) {
+	var cache encoderCache
+	assert.Equal(t, EncoderCacheStats{Capacity: DefaultEncoderCacheSize}, cache.stats())
+	_, err := cache.get(1, func() (reedsolomon.Encoder, error) {
+		return reedsolomon.New(1, 1)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, cache.stats().Size)
+}
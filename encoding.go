@@ -0,0 +1,636 @@
+package rsmt2d
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// binaryMagic identifies the binary serialization of an ExtendedDataSquare.
+var binaryMagic = [4]byte{'R', 'S', 'M', 'T'}
+
+// binaryVersion is the version of the binary serialization of an
+// ExtendedDataSquare.
+const binaryVersion = 1
+
+// Flags of the binary serialization of an ExtendedDataSquare.
+const (
+	// flagRoots is set if the row and column roots are included.
+	flagRoots = 1 << iota
+	// flagMissingShares is set if some shares are missing, in which case a
+	// bitmap of the present shares precedes the shares.
+	flagMissingShares
+	// flagOriginalData is set if only the shares of the original data are
+	// included, from which the square is re-extended when decoding.
+	flagOriginalData
+	// flagTreeName is set if the name of the tree, as returned by
+	// NamedTree or unnamedTreeName, follows the names of the codecs. It is
+	// always set by Encoder.
+	flagTreeName
+)
+
+// unnamedTreeName is recorded instead of the name of a tree that does not
+// implement NamedTree. Such a square can only be decoded with an explicit
+// Decoder.TreeConstructor.
+const unnamedTreeName = "<unnamed>"
+
+// encodedTreeName returns the name of the trees created by fn as recorded in
+// an encoding.
+func encodedTreeName(fn TreeConstructorFn) string {
+	if name := treeName(fn); name != "" {
+		return name
+	}
+	return unnamedTreeName
+}
+
+// ErrInvalidBinaryFormat is returned when decoding data that is not an
+// ExtendedDataSquare serialized with the binary format.
+var ErrInvalidBinaryFormat = errors.New("invalid extended data square binary format")
+
+// Encoder serializes an ExtendedDataSquare in a compact binary format. The
+// format consists of a header with the names of the codecs and tree, the
+// dimensions of the square and its original data, the share size and
+// optionally the row and column roots, followed by the raw shares in
+// row-major order.
+type Encoder struct {
+	// IncludeRoots includes the row and column roots, which are verified
+	// when decoding.
+	IncludeRoots bool
+	// OriginalDataOnly includes only the shares of the original data, which
+	// must be complete. Since parity shares are deterministic, the square is
+	// re-extended when decoding.
+	OriginalDataOnly bool
+}
+
+// Encode writes eds to w and returns the number of bytes written.
+func (e Encoder) Encode(w io.Writer, eds *ExtendedDataSquare) (int64, error) {
+	header, err := e.appendHeader(nil, eds)
+	if err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+	written, err := bw.Write(header)
+	n := int64(written)
+	if err != nil {
+		return n, err
+	}
+	height, width := eds.height, eds.width
+	if e.OriginalDataOnly {
+		height, width = eds.originalDataHeight, eds.originalDataWidth
+	}
+	for _, row := range eds.squareRow[:height] {
+		for _, share := range row[:width] {
+			written, err := bw.Write(share)
+			n += int64(written)
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, bw.Flush()
+}
+
+// Marshal returns the binary serialization of eds.
+func (e Encoder) Marshal(eds *ExtendedDataSquare) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(int(eds.width * eds.height * eds.chunkSize))
+	if _, err := e.Encode(&buf, eds); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (e Encoder) appendHeader(buf []byte, eds *ExtendedDataSquare) ([]byte, error) {
+	var flags byte
+	if e.IncludeRoots {
+		flags |= flagRoots
+	}
+	var bitmap []byte
+	if e.OriginalDataOnly {
+		flags |= flagOriginalData
+		if err := eds.checkOriginalData(); err != nil {
+			return nil, err
+		}
+	} else {
+		bitmap = make([]byte, (eds.width*eds.height+7)/8)
+		for r, row := range eds.squareRow {
+			for c, share := range row {
+				if share == nil {
+					flags |= flagMissingShares
+				} else {
+					i := uint(r)*eds.width + uint(c)
+					bitmap[i/8] |= 1 << (i % 8)
+				}
+			}
+		}
+	}
+	name := encodedTreeName(eds.createTreeFn)
+	flags |= flagTreeName
+
+	buf = append(buf, binaryMagic[:]...)
+	buf = append(buf, binaryVersion, flags)
+	buf = appendBytes(buf, []byte(eds.codec.Name()))
+	buf = appendBytes(buf, []byte(eds.colCodec.Name()))
+	buf = appendBytes(buf, []byte(name))
+	buf = appendUvarint(buf, uint64(eds.width))
+	buf = appendUvarint(buf, uint64(eds.height))
+	buf = appendUvarint(buf, uint64(eds.chunkSize))
+	buf = appendUvarint(buf, uint64(eds.originalDataWidth))
+	buf = appendUvarint(buf, uint64(eds.originalDataHeight))
+
+	if e.IncludeRoots {
+		rowRoots, err := eds.getRowRoots()
+		if err != nil {
+			return nil, err
+		}
+		colRoots, err := eds.getColRoots()
+		if err != nil {
+			return nil, err
+		}
+		for _, root := range append(rowRoots[:len(rowRoots):len(rowRoots)], colRoots...) {
+			buf = appendBytes(buf, root)
+		}
+	}
+	if flags&flagMissingShares != 0 {
+		buf = append(buf, bitmap...)
+	}
+	return buf, nil
+}
+
+// EncodeJSON returns the JSON serialization of eds. The roots and the name of
+// the tree are included as in the binary format.
+func (e Encoder) EncodeJSON(eds *ExtendedDataSquare) ([]byte, error) {
+	aux := jsonExtendedDataSquare{
+		DataSquare: eds.Flattened(),
+		Codec:      eds.codec.Name(),
+		Tree:       encodedTreeName(eds.createTreeFn),
+	}
+	height := eds.height
+	if e.OriginalDataOnly {
+		if err := eds.checkOriginalData(); err != nil {
+			return nil, err
+		}
+		aux.DataSquare = aux.DataSquare[:0]
+		for _, row := range eds.squareRow[:eds.originalDataHeight] {
+			aux.DataSquare = append(aux.DataSquare, row[:eds.originalDataWidth]...)
+		}
+		aux.OriginalData = true
+		height = eds.originalDataHeight
+	}
+	if eds.isRectangle() {
+		aux.Height = height
+		aux.ColCodec = eds.colCodec.Name()
+	}
+	if e.IncludeRoots {
+		var err error
+		if aux.RowRoots, err = eds.getRowRoots(); err != nil {
+			return nil, err
+		}
+		if aux.ColRoots, err = eds.getColRoots(); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(&aux)
+}
+
+// jsonExtendedDataSquare is the JSON serialization of an ExtendedDataSquare.
+// Height and ColCodec are only set for rectangles.
+type jsonExtendedDataSquare struct {
+	DataSquare   [][]byte `json:"data_square"`
+	Codec        string   `json:"codec"`
+	Height       uint     `json:"height,omitempty"`
+	ColCodec     string   `json:"col_codec,omitempty"`
+	OriginalData bool     `json:"original_data,omitempty"`
+	Tree         string   `json:"tree,omitempty"`
+	RowRoots     [][]byte `json:"row_roots,omitempty"`
+	ColRoots     [][]byte `json:"column_roots,omitempty"`
+}
+
+// checkOriginalData returns an error if a share of the original data is
+// missing.
+func (eds *ExtendedDataSquare) checkOriginalData() error {
+	for r := uint(0); r < eds.originalDataHeight; r++ {
+		for c := uint(0); c < eds.originalDataWidth; c++ {
+			if eds.squareRow[r][c] == nil {
+				return fmt.Errorf("cannot encode original data: share (%d, %d) is missing", r, c)
+			}
+		}
+	}
+	return nil
+}
+
+// Decoder deserializes an ExtendedDataSquare encoded by an Encoder.
+type Decoder struct {
+	// TreeConstructor creates the trees of the decoded square. If nil,
+	// NewDefaultTree is used. If the encoding records the name of a tree, the
+	// trees created by TreeConstructor must have the same name. A square
+	// encoded with trees that do not implement NamedTree can only be decoded
+	// with an explicit TreeConstructor, which is then trusted to match. If the roots
+	// are included in the encoding, they are verified with trees created by
+	// TreeConstructor.
+	TreeConstructor TreeConstructorFn
+}
+
+// Decode reads an ExtendedDataSquare from r and returns it along with the
+// number of bytes read.
+func (d Decoder) Decode(r io.Reader) (*ExtendedDataSquare, int64, error) {
+	cr := &countingReader{r: r, size: -1}
+	if br, ok := r.(io.ByteReader); ok {
+		cr.br = br
+	} else {
+		br := bufio.NewReader(r)
+		cr.r, cr.br = br, br
+	}
+	eds, err := d.decode(cr)
+	return eds, cr.n, err
+}
+
+// Unmarshal decodes the binary serialization of an ExtendedDataSquare.
+func (d Decoder) Unmarshal(data []byte) (*ExtendedDataSquare, error) {
+	br := bytes.NewReader(data)
+	r := &countingReader{r: br, br: br, size: int64(len(data))}
+	eds, err := d.decode(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.n != int64(len(data)) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidBinaryFormat, int64(len(data))-r.n)
+	}
+	return eds, nil
+}
+
+// decode reads an ExtendedDataSquare from r.
+func (d Decoder) decode(r *countingReader) (*ExtendedDataSquare, error) {
+	header := make([]byte, len(binaryMagic)+2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(binaryMagic)], binaryMagic[:]) {
+		return nil, fmt.Errorf("%w: unexpected magic bytes %x", ErrInvalidBinaryFormat, header[:len(binaryMagic)])
+	}
+	if version := header[len(binaryMagic)]; version != binaryVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidBinaryFormat, version)
+	}
+	flags := header[len(binaryMagic)+1]
+
+	var axisCodecs [2]Codec
+	for i := range axisCodecs {
+		name, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		if axisCodecs[i], err = LookupCodec(string(name)); err != nil {
+			return nil, err
+		}
+		if err := validateExtensionFactor(axisCodecs[i]); err != nil {
+			return nil, err
+		}
+	}
+	rowCodec, colCodec := axisCodecs[0], axisCodecs[1]
+
+	var name []byte
+	if flags&flagTreeName != 0 {
+		var err error
+		if name, err = readBytes(r); err != nil {
+			return nil, err
+		}
+	}
+	treeFn, err := d.treeConstructor(string(name))
+	if err != nil {
+		return nil, err
+	}
+
+	var dims [5]uint64
+	for i := range dims {
+		if dims[i], err = readUvarint(r, maxUint); err != nil {
+			return nil, err
+		}
+	}
+	width, height, shareSize, originalDataWidth, originalDataHeight := dims[0], dims[1], dims[2], dims[3], dims[4]
+	// Bound the original dimensions first so that the products below cannot
+	// overflow.
+	if originalDataWidth > uint64(rowCodec.MaxChunks()) || originalDataHeight > uint64(colCodec.MaxChunks()) ||
+		originalDataWidth*originalDataWidth > uint64(rowCodec.MaxChunks()) ||
+		originalDataHeight*originalDataHeight > uint64(colCodec.MaxChunks()) {
+		return nil, errors.New("number of chunks exceeds the maximum")
+	}
+	if originalDataWidth*uint64(extensionFactor(rowCodec)) != width || originalDataHeight*uint64(extensionFactor(colCodec)) != height {
+		return nil, fmt.Errorf("%w: %dx%d square cannot extend %dx%d original data with codecs %s and %s",
+			ErrInvalidBinaryFormat, height, width, originalDataHeight, originalDataWidth, rowCodec.Name(), colCodec.Name())
+	}
+	if shareSize == 0 && flags&flagMissingShares == 0 {
+		return nil, fmt.Errorf("%w: zero share size", ErrInvalidBinaryFormat)
+	}
+	// The dimensions are untrusted, so nothing is allocated for them before
+	// the input is known to be large enough, or before it has been read if
+	// its size is unknown.
+	var roots [][]byte
+	if flags&flagRoots != 0 {
+		if err := r.checkRemaining(height+width, 1, "roots"); err != nil {
+			return nil, err
+		}
+		roots = make([][]byte, 0, r.capacity(height+width))
+		for i := uint64(0); i < height+width; i++ {
+			root, err := readBytes(r)
+			if err != nil {
+				return nil, err
+			}
+			roots = append(roots, root)
+		}
+	}
+
+	var eds *ExtendedDataSquare
+	if flags&flagOriginalData != 0 {
+		if flags&flagMissingShares != 0 {
+			return nil, fmt.Errorf("%w: original data cannot have missing shares", ErrInvalidBinaryFormat)
+		}
+		cells := originalDataWidth * originalDataHeight
+		if err := r.checkRemaining(cells, shareSize, "original data"); err != nil {
+			return nil, err
+		}
+		data := make([][]byte, 0, r.capacity(cells))
+		for i := uint64(0); i < cells; i++ {
+			share, err := r.readShare(shareSize)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, share)
+		}
+		eds, err = ComputeExtendedDataRectangle(data, uint(originalDataHeight), rowCodec, colCodec, treeFn)
+	} else {
+		cells := width * height
+		present := cells
+		var bitmap []byte
+		if flags&flagMissingShares != 0 {
+			bitmapLen := (cells + 7) / 8
+			if err := r.checkRemaining(bitmapLen, 1, "bitmap"); err != nil {
+				return nil, err
+			}
+			if bitmap, err = io.ReadAll(io.LimitReader(r, int64(bitmapLen))); err != nil {
+				return nil, err
+			}
+			if uint64(len(bitmap)) != bitmapLen {
+				return nil, io.ErrUnexpectedEOF
+			}
+			present = 0
+			for i := uint64(0); i < cells; i++ {
+				if bitmap[i/8]&(1<<(i%8)) != 0 {
+					present++
+				}
+			}
+			if present > 0 && shareSize == 0 {
+				return nil, fmt.Errorf("%w: zero share size", ErrInvalidBinaryFormat)
+			}
+		}
+		if err := r.checkRemaining(present, shareSize, "shares"); err != nil {
+			return nil, err
+		}
+		// Once the bitmap has been read, the number of cells is bounded by
+		// the size of the input.
+		capacity := cells
+		if bitmap == nil {
+			capacity = r.capacity(cells)
+		}
+		data := make([][]byte, 0, capacity)
+		for i := uint64(0); i < cells; i++ {
+			if bitmap != nil && bitmap[i/8]&(1<<(i%8)) == 0 {
+				data = append(data, nil)
+				continue
+			}
+			share, err := r.readShare(shareSize)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, share)
+		}
+		eds, err = ImportExtendedDataRectangle(data, uint(height), rowCodec, colCodec, treeFn)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if roots != nil {
+		if err := eds.verifyRoots(roots[:height], roots[height:]); err != nil {
+			return nil, err
+		}
+	}
+	return eds, nil
+}
+
+// DecodeJSON decodes the JSON serialization of an ExtendedDataSquare. The
+// roots and the name of the tree are verified as in the binary format.
+func (d Decoder) DecodeJSON(b []byte) (*ExtendedDataSquare, error) {
+	var aux jsonExtendedDataSquare
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return nil, err
+	}
+	codec, err := LookupCodec(aux.Codec)
+	if err != nil {
+		return nil, err
+	}
+	colCodec := codec
+	if aux.ColCodec != "" {
+		if colCodec, err = LookupCodec(aux.ColCodec); err != nil {
+			return nil, err
+		}
+	}
+	treeFn, err := d.treeConstructor(aux.Tree)
+	if err != nil {
+		return nil, err
+	}
+
+	var eds *ExtendedDataSquare
+	switch {
+	case aux.OriginalData && aux.Height == 0:
+		eds, err = ComputeExtendedDataSquare(aux.DataSquare, codec, treeFn)
+	case aux.OriginalData:
+		eds, err = ComputeExtendedDataRectangle(aux.DataSquare, aux.Height, codec, colCodec, treeFn)
+	case aux.Height == 0:
+		eds, err = ImportExtendedDataSquare(aux.DataSquare, codec, treeFn)
+	default:
+		eds, err = ImportExtendedDataRectangle(aux.DataSquare, aux.Height, codec, colCodec, treeFn)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if aux.RowRoots != nil || aux.ColRoots != nil {
+		if err := eds.verifyRoots(aux.RowRoots, aux.ColRoots); err != nil {
+			return nil, err
+		}
+	}
+	return eds, nil
+}
+
+// treeConstructor returns the tree constructor of the decoder and checks that
+// its trees have the name recorded in an encoding, unless name is empty. A
+// square encoded with an unnamed tree requires an explicit TreeConstructor.
+func (d Decoder) treeConstructor(name string) (TreeConstructorFn, error) {
+	treeFn := d.TreeConstructor
+	if name == unnamedTreeName {
+		if treeFn == nil {
+			return nil, errors.New("square encoded with a tree that does not implement NamedTree cannot be decoded without a TreeConstructor")
+		}
+		return treeFn, nil
+	}
+	if treeFn == nil {
+		treeFn = NewDefaultTree
+	}
+	if name != "" {
+		if got := treeName(treeFn); got != name {
+			return nil, fmt.Errorf("square encoded with tree %q cannot be decoded with tree %q", name, got)
+		}
+	}
+	return treeFn, nil
+}
+
+// verifyRoots checks that the roots of the square are rowRoots and colRoots.
+func (eds *ExtendedDataSquare) verifyRoots(rowRoots [][]byte, colRoots [][]byte) error {
+	if uint(len(rowRoots)) != eds.height || uint(len(colRoots)) != eds.width {
+		return fmt.Errorf("expected %d row roots and %d column roots, got %d and %d",
+			eds.height, eds.width, len(rowRoots), len(colRoots))
+	}
+	computedRowRoots, err := eds.getRowRoots()
+	if err != nil {
+		return err
+	}
+	computedColRoots, err := eds.getColRoots()
+	if err != nil {
+		return err
+	}
+	for i, root := range computedRowRoots {
+		if !bytes.Equal(root, rowRoots[i]) {
+			return &ErrRootMismatch{Row, uint(i), rowRoots[i], root}
+		}
+	}
+	for i, root := range computedColRoots {
+		if !bytes.Equal(root, colRoots[i]) {
+			return &ErrRootMismatch{Col, uint(i), colRoots[i], root}
+		}
+	}
+	return nil
+}
+
+// countingReader counts the bytes read from r. If size is not negative, it is
+// the number of bytes available from r.
+type countingReader struct {
+	r    io.Reader
+	br   io.ByteReader
+	n    int64
+	size int64
+	// sharesRead is set once a share has been read.
+	sharesRead bool
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *countingReader) ReadByte() (byte, error) {
+	b, err := r.br.ReadByte()
+	if err == nil {
+		r.n++
+	}
+	return b, err
+}
+
+// readShare reads a share of shareSize bytes. Until a first share has been
+// read from a stream of unknown size, the share is read incrementally so that
+// a corrupted share size cannot cause a large allocation.
+func (r *countingReader) readShare(shareSize uint64) ([]byte, error) {
+	if r.size >= 0 || r.sharesRead {
+		share := make([]byte, shareSize)
+		if _, err := io.ReadFull(r, share); err != nil {
+			return nil, err
+		}
+		return share, nil
+	}
+	share, err := io.ReadAll(io.LimitReader(r, int64(shareSize)))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(share)) != shareSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+	r.sharesRead = true
+	return share, nil
+}
+
+// checkRemaining returns an error if the input has a known size and fewer
+// than n items of size bytes each are left to be read from it.
+func (r *countingReader) checkRemaining(n uint64, size uint64, what string) error {
+	if r.size < 0 {
+		return nil
+	}
+	remaining := uint64(r.size - r.n)
+	if size != 0 && n > remaining/size {
+		return fmt.Errorf("%w: %d %s of %d bytes exceed the remaining %d bytes: %w",
+			ErrInvalidBinaryFormat, n, what, size, remaining, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// capacity returns the capacity to allocate for n items that are read from r.
+// If the size of the input is unknown, it is bounded by maxStreamFieldLength,
+// so that a corrupted header cannot cause a large allocation before the
+// items have been read.
+func (r *countingReader) capacity(n uint64) uint64 {
+	if r.size < 0 && n > maxStreamFieldLength {
+		return maxStreamFieldLength
+	}
+	return n
+}
+
+// maxStreamFieldLength bounds the length of the codec names and roots read
+// from a stream of unknown size.
+const maxStreamFieldLength = 1 << 16
+
+// Len returns the number of bytes available from r, or maxStreamFieldLength
+// if it is unknown.
+func (r *countingReader) Len() int {
+	if r.size < 0 {
+		return maxStreamFieldLength
+	}
+	return int(r.size - r.n)
+}
+
+// MarshalBinary encodes the square in the binary format of Encoder, without
+// roots.
+func (eds *ExtendedDataSquare) MarshalBinary() ([]byte, error) {
+	return Encoder{}.Marshal(eds)
+}
+
+// UnmarshalBinary decodes a square encoded in the binary format of Encoder,
+// using NewDefaultTree as the tree constructor.
+func (eds *ExtendedDataSquare) UnmarshalBinary(data []byte) error {
+	decoded, err := Decoder{}.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	*eds = *decoded
+	return nil
+}
+
+// WriteTo writes the square to w in the binary format of Encoder, without
+// roots.
+func (eds *ExtendedDataSquare) WriteTo(w io.Writer) (int64, error) {
+	return Encoder{}.Encode(w, eds)
+}
+
+// ReadFrom reads a square in the binary format of Encoder from r, using
+// NewDefaultTree as the tree constructor. If r does not implement
+// io.ByteReader, it may be read past the end of the square.
+func (eds *ExtendedDataSquare) ReadFrom(r io.Reader) (int64, error) {
+	decoded, n, err := Decoder{}.Decode(r)
+	if err != nil {
+		return n, err
+	}
+	*eds = *decoded
+	return n, nil
+}
@@ -0,0 +1,458 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"runtime"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoderRoundTrip(t *testing.T) {
+	for codecName, codec := range codecs {
+		complete, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+		require.NoError(t, err)
+		incomplete, err := complete.deepCopy()
+		require.NoError(t, err)
+		for _, cell := range []Cell{{0, 0}, {1, 5}, {7, 7}} {
+			incomplete.squareRow[cell.Row][cell.Col] = nil
+			incomplete.squareCol[cell.Col][cell.Row] = nil
+		}
+		incomplete.resetRoots()
+
+		tests := []struct {
+			name    string
+			eds     *ExtendedDataSquare
+			encoder Encoder
+		}{
+			{"complete", complete, Encoder{}},
+			{"complete with roots", complete, Encoder{IncludeRoots: true}},
+			{"incomplete", &incomplete, Encoder{}},
+			{"incomplete with roots", &incomplete, Encoder{IncludeRoots: true}},
+		}
+		for _, tt := range tests {
+			t.Run(codecName+"/"+tt.name, func(t *testing.T) {
+				data, err := tt.encoder.Marshal(tt.eds)
+				require.NoError(t, err)
+
+				decoded, err := Decoder{}.Unmarshal(data)
+				require.NoError(t, err)
+				assert.Equal(t, tt.eds.squareRow, decoded.squareRow)
+				assert.Equal(t, tt.eds.originalDataWidth, decoded.originalDataWidth)
+				assert.Equal(t, tt.eds.codec.Name(), decoded.codec.Name())
+
+				wantRoots, err := tt.eds.RowRoots()
+				require.NoError(t, err)
+				gotRoots, err := decoded.RowRoots()
+				require.NoError(t, err)
+				assert.Equal(t, wantRoots, gotRoots)
+
+				var buf bytes.Buffer
+				n, err := tt.encoder.Encode(&buf, tt.eds)
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(data)), n)
+				assert.Equal(t, data, buf.Bytes())
+
+				// a reader that is not an io.ByteReader is buffered
+				decoded, n, err = Decoder{}.Decode(iotest.OneByteReader(bytes.NewReader(data)))
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(data)), n)
+				assert.Equal(t, tt.eds.squareRow, decoded.squareRow)
+			})
+		}
+	}
+}
+
+func TestExtendedDataSquareBinaryMethods(t *testing.T) {
+	eds := createTestEds(NewLeoRSCodec(), ShardSize)
+
+	data, err := eds.MarshalBinary()
+	require.NoError(t, err)
+	var decoded ExtendedDataSquare
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, eds.squareRow, decoded.squareRow)
+
+	var buf bytes.Buffer
+	n, err := eds.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+
+	// the square is followed by unrelated data in the stream
+	buf.WriteString("trailer")
+	var streamed ExtendedDataSquare
+	n, err = streamed.ReadFrom(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	assert.Equal(t, eds.squareRow, streamed.squareRow)
+	assert.Equal(t, "trailer", buf.String())
+
+	// the binary format is more compact than JSON
+	jsonData, err := eds.MarshalJSON()
+	require.NoError(t, err)
+	assert.Less(t, len(data), len(jsonData))
+}
+
+func TestEncoderRectangle(t *testing.T) {
+	rowCodec := NewLeoRSCodec()
+	colCodec := NewLeoRSCodec(WithExtensionFactor(4))
+	require.NoError(t, RegisterCodec(colCodec.Name(), colCodec))
+	defer UnregisterCodec(colCodec.Name())
+
+	eds, err := ComputeExtendedDataRectangle(genRandDS(2)[:2], 1, rowCodec, colCodec, NewDefaultTree)
+	require.NoError(t, err)
+
+	data, err := Encoder{IncludeRoots: true}.Marshal(eds)
+	require.NoError(t, err)
+	decoded, err := Decoder{}.Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, eds.squareRow, decoded.squareRow)
+	assert.Equal(t, eds.originalDataHeight, decoded.originalDataHeight)
+	assert.Equal(t, colCodec.Name(), decoded.colCodec.Name())
+}
+
+func TestDecoderTreeConstructor(t *testing.T) {
+	eds := createTestEds(NewLeoRSCodec(), ShardSize)
+	data, err := Encoder{IncludeRoots: true}.Marshal(eds)
+	require.NoError(t, err)
+
+	// the included roots do not match the roots of a different tree
+	_, err = Decoder{TreeConstructor: newSaltedTree}.Unmarshal(data)
+	var mismatch *ErrRootMismatch
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, Row, mismatch.Axis)
+	assert.Equal(t, uint(0), mismatch.Index)
+
+	// without roots the square is decoded with the given tree
+	data, err = Encoder{}.Marshal(eds)
+	require.NoError(t, err)
+	decoded, err := Decoder{TreeConstructor: newSaltedTree}.Unmarshal(data)
+	require.NoError(t, err)
+	wantRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	gotRoots, err := decoded.RowRoots()
+	require.NoError(t, err)
+	assert.NotEqual(t, wantRoots, gotRoots)
+}
+
+func TestDecoderInvalidData(t *testing.T) {
+	eds := createTestEds(NewLeoRSCodec(), ShardSize)
+	data, err := Encoder{IncludeRoots: true}.Marshal(eds)
+	require.NoError(t, err)
+
+	modify := func(f func([]byte) []byte) []byte {
+		return f(append([]byte(nil), data...))
+	}
+	codecName := []byte(eds.codec.Name())
+	dimsOffset := len(binaryMagic) + 2 + 2*(1+len(codecName)) + 1 + len(defaultTreeName)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"empty", nil, io.EOF},
+		{"bad magic", modify(func(b []byte) []byte { b[0] = 'X'; return b }), ErrInvalidBinaryFormat},
+		{"bad version", modify(func(b []byte) []byte { b[len(binaryMagic)]++; return b }), ErrInvalidBinaryFormat},
+		{"trailing bytes", append(append([]byte(nil), data...), 0), ErrInvalidBinaryFormat},
+		{"truncated", data[:len(data)-1], io.ErrUnexpectedEOF},
+		{"inconsistent width", modify(func(b []byte) []byte { b[dimsOffset]++; return b }), ErrInvalidBinaryFormat},
+		{"corrupted share", modify(func(b []byte) []byte { b[len(b)-1]++; return b }), &ErrRootMismatch{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decoder{}.Unmarshal(tt.data)
+			require.Error(t, err)
+			if mismatch, ok := tt.wantErr.(*ErrRootMismatch); ok {
+				assert.ErrorAs(t, err, &mismatch)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("unknown codec", func(t *testing.T) {
+		unknown := modify(func(b []byte) []byte {
+			b[len(binaryMagic)+3]++
+			return b
+		})
+		_, err := Decoder{}.Unmarshal(unknown)
+		var unknownErr *ErrUnknownCodec
+		assert.ErrorAs(t, err, &unknownErr)
+	})
+}
+
+// hugeHeader returns the header of an encoding of a 4096x4096 square that is
+// not followed by any data.
+func hugeHeader(flags byte) []byte {
+	buf := append([]byte(nil), binaryMagic[:]...)
+	buf = append(buf, binaryVersion, flags)
+	buf = appendBytes(buf, []byte(Leopard))
+	buf = appendBytes(buf, []byte(Leopard))
+	for _, dim := range []uint64{4096, 4096, 64, 2048, 2048} {
+		buf = appendUvarint(buf, dim)
+	}
+	return buf
+}
+
+func TestDecoderHugeDimensions(t *testing.T) {
+	decoders := map[string]func(data []byte) error{
+		"unmarshal": func(data []byte) error {
+			_, err := Decoder{}.Unmarshal(data)
+			return err
+		},
+		"stream": func(data []byte) error {
+			_, _, err := Decoder{}.Decode(iotest.OneByteReader(bytes.NewReader(data)))
+			return err
+		},
+	}
+	for name, decode := range decoders {
+		for _, flags := range []byte{0, flagMissingShares, flagOriginalData, flagRoots} {
+			data := hugeHeader(flags)
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			err := decode(data)
+			runtime.ReadMemStats(&after)
+
+			assert.Error(t, err, "%s with flags %d", name, flags)
+			assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(8<<20), "%s with flags %d", name, flags)
+		}
+	}
+
+	t.Run("zero share size", func(t *testing.T) {
+		eds := createTestEds(NewLeoRSCodec(), ShardSize)
+		data, err := Encoder{}.Marshal(eds)
+		require.NoError(t, err)
+		shareSizeOffset := len(binaryMagic) + 2 + 2*(1+len(Leopard)) + 1 + len(defaultTreeName) + 2
+		require.Equal(t, byte(ShardSize), data[shareSizeOffset])
+		data[shareSizeOffset] = 0
+		_, err = Decoder{}.Unmarshal(data)
+		assert.ErrorIs(t, err, ErrInvalidBinaryFormat)
+	})
+}
+
+func FuzzDecoder(f *testing.F) {
+	eds := createTestEds(NewLeoRSCodec(), ShardSize)
+	for _, encoder := range []Encoder{{}, {IncludeRoots: true}, {OriginalDataOnly: true}} {
+		data, err := encoder.Marshal(eds)
+		require.NoError(f, err)
+		f.Add(data)
+	}
+	incomplete := createTestEds(NewLeoRSCodec(), ShardSize)
+	incomplete.setCell(1, 2, nil)
+	data, err := Encoder{}.Marshal(incomplete)
+	require.NoError(f, err)
+	f.Add(data)
+	for _, flags := range []byte{0, flagMissingShares, flagOriginalData, flagRoots} {
+		f.Add(hugeHeader(flags))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded, err := Decoder{}.Unmarshal(data)
+		if err != nil {
+			return
+		}
+		// whatever decodes can be encoded again
+		_, err = Encoder{}.Marshal(decoded)
+		assert.NoError(t, err)
+	})
+}
+
+func TestEncoderOriginalDataOnly(t *testing.T) {
+	for codecName, codec := range codecs {
+		t.Run(codecName, func(t *testing.T) {
+			eds, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+			require.NoError(t, err)
+			full, err := Encoder{}.Marshal(eds)
+			require.NoError(t, err)
+
+			data, err := Encoder{OriginalDataOnly: true, IncludeRoots: true}.Marshal(eds)
+			require.NoError(t, err)
+			assert.Less(t, len(data), len(full)/2)
+
+			decoded, err := Decoder{}.Unmarshal(data)
+			require.NoError(t, err)
+			assert.Equal(t, eds.squareRow, decoded.squareRow)
+
+			var buf bytes.Buffer
+			_, err = Encoder{OriginalDataOnly: true}.Encode(&buf, eds)
+			require.NoError(t, err)
+			decoded, _, err = Decoder{}.Decode(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, eds.squareRow, decoded.squareRow)
+		})
+	}
+
+	t.Run("rectangle", func(t *testing.T) {
+		rowCodec := NewLeoRSCodec()
+		colCodec := NewLeoRSCodec(WithExtensionFactor(4))
+		require.NoError(t, RegisterCodec(colCodec.Name(), colCodec))
+		defer UnregisterCodec(colCodec.Name())
+
+		eds, err := ComputeExtendedDataRectangle(genRandDS(2)[:2], 1, rowCodec, colCodec, NewDefaultTree)
+		require.NoError(t, err)
+		data, err := Encoder{OriginalDataOnly: true}.Marshal(eds)
+		require.NoError(t, err)
+		decoded, err := Decoder{}.Unmarshal(data)
+		require.NoError(t, err)
+		assert.Equal(t, eds.squareRow, decoded.squareRow)
+	})
+
+	t.Run("missing parity shares", func(t *testing.T) {
+		eds := createTestEds(NewLeoRSCodec(), ShardSize)
+		complete, err := eds.deepCopy()
+		require.NoError(t, err)
+		eds.squareRow[3][3] = nil
+		eds.squareCol[3][3] = nil
+
+		data, err := Encoder{OriginalDataOnly: true}.Marshal(eds)
+		require.NoError(t, err)
+		decoded, err := Decoder{}.Unmarshal(data)
+		require.NoError(t, err)
+		assert.Equal(t, complete.squareRow, decoded.squareRow)
+	})
+
+	t.Run("missing original data", func(t *testing.T) {
+		eds := createTestEds(NewLeoRSCodec(), ShardSize)
+		eds.squareRow[1][0] = nil
+		eds.squareCol[0][1] = nil
+
+		_, err := Encoder{OriginalDataOnly: true}.Marshal(eds)
+		assert.Error(t, err)
+	})
+
+	t.Run("roots of bad encoding", func(t *testing.T) {
+		eds := createTestEds(NewLeoRSCodec(), ShardSize)
+		eds.squareRow[0][3][0]++
+
+		// the roots of the corrupted square do not match the re-extended
+		// square
+		data, err := Encoder{OriginalDataOnly: true, IncludeRoots: true}.Marshal(eds)
+		require.NoError(t, err)
+		_, err = Decoder{}.Unmarshal(data)
+		var mismatch *ErrRootMismatch
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, Row, mismatch.Axis)
+		assert.Equal(t, uint(0), mismatch.Index)
+	})
+}
+
+func TestDecoderTreeName(t *testing.T) {
+	eds := createTestEds(NewLeoRSCodec(), ShardSize)
+	data, err := Encoder{}.Marshal(eds)
+	require.NoError(t, err)
+
+	_, err = Decoder{TreeConstructor: newNamedTree("other")}.Unmarshal(data)
+	assert.Error(t, err)
+	_, err = Decoder{TreeConstructor: newErrorTree}.Unmarshal(data)
+	assert.Error(t, err)
+	_, err = Decoder{TreeConstructor: newNamedTree(defaultTreeName)}.Unmarshal(data)
+	assert.NoError(t, err)
+
+	// a square built with an unnamed tree requires an explicit tree
+	// constructor
+	imported, err := ImportExtendedDataSquare(eds.Flattened(), eds.codec, newErrorTree)
+	require.NoError(t, err)
+	data, err = Encoder{}.Marshal(imported)
+	require.NoError(t, err)
+	_, err = Decoder{}.Unmarshal(data)
+	assert.Error(t, err)
+	_, err = Decoder{TreeConstructor: newNamedTree("other")}.Unmarshal(data)
+	assert.NoError(t, err)
+
+	jsonData, err := Encoder{}.EncodeJSON(imported)
+	require.NoError(t, err)
+	_, err = Decoder{}.DecodeJSON(jsonData)
+	assert.Error(t, err)
+	_, err = Decoder{TreeConstructor: newNamedTree("other")}.DecodeJSON(jsonData)
+	assert.NoError(t, err)
+}
+
+func TestEncoderJSON(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewLeoRSCodec(), newNamedTree("custom"))
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+
+	for _, encoder := range []Encoder{{}, {IncludeRoots: true}, {OriginalDataOnly: true}, {OriginalDataOnly: true, IncludeRoots: true}} {
+		data, err := encoder.EncodeJSON(eds)
+		require.NoError(t, err)
+
+		decoded, err := Decoder{TreeConstructor: newNamedTree("custom")}.DecodeJSON(data)
+		require.NoError(t, err)
+		assert.Equal(t, eds.squareRow, decoded.squareRow)
+		decodedRoots, err := decoded.RowRoots()
+		require.NoError(t, err)
+		assert.Equal(t, rowRoots, decodedRoots)
+
+		// the square is not silently decoded with the default tree
+		var defaultDecoded ExtendedDataSquare
+		assert.Error(t, json.Unmarshal(data, &defaultDecoded))
+	}
+
+	t.Run("roots", func(t *testing.T) {
+		eds := createTestEds(NewLeoRSCodec(), ShardSize)
+		data, err := Encoder{IncludeRoots: true}.EncodeJSON(eds)
+		require.NoError(t, err)
+		var aux map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(data, &aux))
+		assert.Contains(t, aux, "row_roots")
+		assert.Contains(t, aux, "column_roots")
+
+		var decoded ExtendedDataSquare
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		_, err = Decoder{TreeConstructor: newSaltedTree}.DecodeJSON(data)
+		var mismatch *ErrRootMismatch
+		assert.ErrorAs(t, err, &mismatch)
+
+		aux["column_roots"] = json.RawMessage("[]")
+		data, err = json.Marshal(aux)
+		require.NoError(t, err)
+		assert.Error(t, json.Unmarshal(data, &decoded))
+	})
+
+	t.Run("rectangle original data", func(t *testing.T) {
+		rowCodec := NewLeoRSCodec()
+		colCodec := NewLeoRSCodec(WithExtensionFactor(4))
+		require.NoError(t, RegisterCodec(colCodec.Name(), colCodec))
+		defer UnregisterCodec(colCodec.Name())
+
+		eds, err := ComputeExtendedDataRectangle(genRandDS(2)[:2], 1, rowCodec, colCodec, NewDefaultTree)
+		require.NoError(t, err)
+		data, err := Encoder{OriginalDataOnly: true, IncludeRoots: true}.EncodeJSON(eds)
+		require.NoError(t, err)
+		var decoded ExtendedDataSquare
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, eds.squareRow, decoded.squareRow)
+	})
+}
+
+// namedTree is a DefaultTree with a different name.
+type namedTree struct {
+	*DefaultTree
+	name string
+}
+
+func (n namedTree) Name() string {
+	return n.name
+}
+
+func newNamedTree(name string) TreeConstructorFn {
+	return func(axis Axis, index uint) Tree {
+		return namedTree{NewDefaultTree(axis, index).(*DefaultTree), name}
+	}
+}
+
+// newSaltedTree returns a DefaultTree with an extra leading leaf, which
+// changes its root.
+func newSaltedTree(axis Axis, index uint) Tree {
+	tree := NewDefaultTree(axis, index)
+	if err := tree.Push([]byte("salt")); err != nil {
+		panic(err)
+	}
+	return tree
+}
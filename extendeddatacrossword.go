@@ -5,8 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-
-	"golang.org/x/sync/errgroup"
+	"sort"
+	"strings"
+	"time"
 )
 
 // Axis represents which of a row or col.
@@ -37,8 +38,10 @@ func (a Axis) String() string {
 var ErrUnrepairableDataSquare = errors.New("failed to solve data square")
 
 // ErrByzantineData is returned when a repaired row or column does not match the
-// expected row or column Merkle root. It is also returned when the parity data
-// from a row or a column is not equal to the encoded original data.
+// expected row or column Merkle root, including when the tree rejects its
+// shares, e.g. because their namespaces are out of order. It is also returned
+// when the parity data from a row or a column is not equal to the encoded
+// original data.
 type ErrByzantineData struct {
 	// Axis describes if this ErrByzantineData is for a row or column.
 	Axis Axis
@@ -50,12 +53,100 @@ type ErrByzantineData struct {
 	// individual inclusion is guaranteed to be provable by the full node (i.e.
 	// shares usable in a bad encoding fraud proof). Missing shares are nil.
 	Shares [][]byte
+	// Proof proves that the row or column is Byzantine. Repair attaches it if
+	// enough of the shares can be proven against the orthogonal roots, i.e.
+	// if enough of the orthogonal columns or rows are complete. Otherwise it
+	// is nil and can be built later with ProveBadEncoding.
+	Proof *BadEncodingProof
 }
 
 func (e *ErrByzantineData) Error() string {
 	return fmt.Sprintf("byzantine %s: %d", e.Axis, e.Index)
 }
 
+// ErrRootMismatch is returned when the root of a complete row or column does
+// not match the expected root, i.e. the shares or the roots are not the ones
+// of the same square.
+type ErrRootMismatch struct {
+	// Axis describes if this ErrRootMismatch is for a row or column.
+	Axis Axis
+	// Index is the row or column index.
+	Index uint
+	// Expected is the provided root.
+	Expected []byte
+	// Got is the root computed from the shares.
+	Got []byte
+}
+
+func (e *ErrRootMismatch) Error() string {
+	return fmt.Sprintf("bad root input: %s %d expected %v got %v", e.Axis, e.Index, e.Expected, e.Got)
+}
+
+// ErrByzantineAxes is returned by Repair if the WithCollectByzantineData
+// option is used and at least one row or column contains Byzantine data. Each
+// ErrByzantineData can be retrieved with errors.As.
+type ErrByzantineAxes struct {
+	// Errors contains an ErrByzantineData for every row and column with
+	// Byzantine data that was found, rows before columns and in the order of
+	// their indices.
+	Errors []*ErrByzantineData
+}
+
+func (e *ErrByzantineAxes) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *ErrByzantineAxes) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// byzantineAxes collects the Byzantine rows and columns found during repair.
+// A nil *byzantineAxes collects nothing.
+type byzantineAxes struct {
+	errs []*ErrByzantineData
+}
+
+// add records err if it is an ErrByzantineData for a row or column that has
+// not been recorded yet. Returns false if err is not recorded because it is
+// not an ErrByzantineData or b is nil.
+func (b *byzantineAxes) add(err error) bool {
+	var byzErr *ErrByzantineData
+	if b == nil || !errors.As(err, &byzErr) {
+		return false
+	}
+	for _, e := range b.errs {
+		if e.Axis == byzErr.Axis && e.Index == byzErr.Index {
+			return true
+		}
+	}
+	b.errs = append(b.errs, byzErr)
+	return true
+}
+
+// err returns an ErrByzantineAxes with the recorded rows and columns, or nil
+// if none were recorded.
+func (b *byzantineAxes) err() error {
+	if b == nil || len(b.errs) == 0 {
+		return nil
+	}
+	errs := append([]*ErrByzantineData(nil), b.errs...)
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].Axis != errs[j].Axis {
+			return errs[i].Axis < errs[j].Axis
+		}
+		return errs[i].Index < errs[j].Index
+	})
+	return &ErrByzantineAxes{Errors: errs}
+}
+
 // Repair attempts to repair an incomplete extended data
 // square (EDS), comparing repaired rows and columns against expected Merkle
 // roots.
@@ -70,55 +161,362 @@ func (e *ErrByzantineData) Error() string {
 // complete. If repairing is unsuccessful, the EDS will be the most-repaired
 // prior to the Byzantine row or column being repaired, and the Byzantine row
 // or column prior to repair is returned in the error with missing shares as
-// nil.
+// nil. The error carries a BadEncodingProof for the row or column if its
+// shares can be proven, see ErrByzantineData.Proof.
+// With the WithCollectByzantineData option, repairing continues past Byzantine
+// rows and columns and all of them are returned in an ErrByzantineAxes.
+//
+// The provided options apply to this call only and take precedence over the
+// options the EDS was created with.
 func (eds *ExtendedDataSquare) Repair(
 	rowRoots [][]byte,
 	colRoots [][]byte,
+	opts ...Option,
+) error {
+	return eds.RepairWithContext(context.Background(), rowRoots, colRoots, opts...)
+}
+
+// RepairWithContext is like Repair, but stops early if ctx is done. In that
+// case ctx.Err() is returned and the EDS is left with the rows and columns
+// that were repaired until then: every inserted share has been verified
+// against the roots, so the EDS is consistent and repairing can be resumed by
+// calling Repair again.
+func (eds *ExtendedDataSquare) RepairWithContext(
+	ctx context.Context,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	opts ...Option,
 ) error {
-	err := eds.prerepairSanityCheck(rowRoots, colRoots)
+	_, err := eds.RepairWithStats(ctx, rowRoots, colRoots, opts...)
+	return err
+}
+
+// RepairWithStats is like RepairWithContext, but also returns a summary of
+// the work done to repair the EDS. The summary is returned even if repairing
+// is unsuccessful.
+func (eds *ExtendedDataSquare) RepairWithStats(
+	ctx context.Context,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	opts ...Option,
+) (RepairStats, error) {
+	if uint(len(rowRoots)) != eds.height || uint(len(colRoots)) != eds.width {
+		return RepairStats{}, fmt.Errorf("expected %d row roots and %d col roots, got %d and %d", eds.height, eds.width, len(rowRoots), len(colRoots))
+	}
+
+	o := eds.opts.with(opts)
+	var byzAxes *byzantineAxes
+	if o.collectByzantineData {
+		byzAxes = &byzantineAxes{}
+	}
+	stats := &repairStats{observer: o.repairObserver}
+
+	start := time.Now()
+	err := eds.prerepairSanityCheck(ctx, o, rowRoots, colRoots, byzAxes, stats)
+	stats.sanityCheckDuration = time.Since(start)
 	if err != nil {
-		return err
+		return stats.summary(), eds.attachBadEncodingProofs(err)
 	}
 
-	return eds.solveCrossword(rowRoots, colRoots)
+	start = time.Now()
+	if o.parallelSolver {
+		err = eds.solveCrosswordParallel(ctx, o, rowRoots, colRoots, byzAxes, stats)
+	} else {
+		err = eds.solveCrossword(ctx, rowRoots, colRoots, byzAxes, stats)
+	}
+	stats.solveDuration = time.Since(start)
+	return stats.summary(), eds.attachBadEncodingProofs(err)
 }
 
-// solveCrossword attempts to iteratively repair an EDS.
+// solveCrossword attempts to iteratively repair an EDS. If byzAxes is not
+// nil, rows and columns with Byzantine data are recorded in byzAxes and left
+// unrepaired instead of aborting the repair.
 func (eds *ExtendedDataSquare) solveCrossword(
+	ctx context.Context,
 	rowRoots [][]byte,
 	colRoots [][]byte,
+	byzAxes *byzantineAxes,
+	stats *repairStats,
 ) error {
 	// Keep repeating until the square is solved
 	for {
+		stats.startIteration()
 		// Track if the entire square is completely solved
 		solved := true
 		// Track if a single iteration of this loop made progress
 		progressMade := false
 
 		// Loop through every row and column, attempt to rebuild each row or column if incomplete
-		for i := 0; i < int(eds.width); i++ {
-			solvedRow, progressMadeRow, err := eds.solveCrosswordRow(i, rowRoots, colRoots)
-			if err != nil {
+		for i := 0; i < int(eds.width) || i < int(eds.height); i++ {
+			if err := ctx.Err(); err != nil {
 				return err
 			}
-			solvedCol, progressMadeCol, err := eds.solveCrosswordCol(i, rowRoots, colRoots)
-			if err != nil {
+			if i < int(eds.height) {
+				solvedRow, progressMadeRow, err := eds.solveCrosswordRow(i, rowRoots, colRoots, stats)
+				if err != nil && !byzAxes.add(err) {
+					return err
+				}
+				solved = solved && solvedRow
+				progressMade = progressMade || progressMadeRow
+			}
+			if i < int(eds.width) {
+				solvedCol, progressMadeCol, err := eds.solveCrosswordCol(i, rowRoots, colRoots, stats)
+				if err != nil && !byzAxes.add(err) {
+					return err
+				}
+				solved = solved && solvedCol
+				progressMade = progressMade || progressMadeCol
+			}
+		}
+
+		if solved {
+			break
+		}
+		if !progressMade {
+			if err := byzAxes.err(); err != nil {
 				return err
 			}
+			return ErrUnrepairableDataSquare
+		}
+	}
+
+	return byzAxes.err()
+}
+
+// solveCrosswordParallel attempts to iteratively repair an EDS like
+// solveCrossword, but in waves: every iteration first decodes all incomplete
+// rows in parallel and then inserts them in the order of their indices, and
+// then does the same for the columns. Since inserting a row does not change
+// the other rows, and inserting a column does not change the other columns,
+// none of the decoded rows and columns are discarded.
+//
+// Without Byzantine data, the repaired shares are the same as with
+// solveCrossword. With Byzantine data, the row or column that is reported may
+// differ, since all rows of an iteration are solved before its columns.
+func (eds *ExtendedDataSquare) solveCrosswordParallel(
+	ctx context.Context,
+	opts options,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	byzAxes *byzantineAxes,
+	stats *repairStats,
+) error {
+	// Keep repeating until the square is solved
+	for {
+		stats.startIteration()
+		// Track if the entire square is completely solved
+		solved := true
+		// Track if a single iteration of this loop made progress
+		progressMade := false
 
-			solved = solved && solvedRow && solvedCol
-			progressMade = progressMade || progressMadeRow || progressMadeCol
+		for _, axis := range []Axis{Row, Col} {
+			solvedWave, progressMadeWave, err := eds.solveCrosswordWave(ctx, opts, axis, rowRoots, colRoots, byzAxes, stats)
+			if err != nil {
+				return err
+			}
+			solved = solved && solvedWave
+			progressMade = progressMade || progressMadeWave
 		}
 
 		if solved {
 			break
 		}
 		if !progressMade {
+			if err := byzAxes.err(); err != nil {
+				return err
+			}
 			return ErrUnrepairableDataSquare
 		}
 	}
 
-	return nil
+	return byzAxes.err()
+}
+
+// decodedAxis is a row or column decoded by solveCrosswordWave.
+type decodedAxis struct {
+	shares        [][]byte
+	rebuiltShares [][]byte
+	err           error
+	// stats records the work done to decode the row or column, which is
+	// added to the stats of the repair once the row or column is solved.
+	stats repairStats
+}
+
+// ok returns whether the row or column was decoded and matches its root.
+func (d *decodedAxis) ok() bool {
+	return d != nil && d.err == nil && d.rebuiltShares != nil
+}
+
+// rootCheck is the result of checking a row or column against its root ahead
+// of inserting the shares that complete it.
+type rootCheck struct {
+	err error
+}
+
+// solveCrosswordWave attempts to repair all rows or all columns of the EDS.
+// The incomplete rows or columns are decoded in parallel, and so are the
+// roots of the orthogonal columns or rows that they complete. The rows or
+// columns are then inserted in the order of their indices, as by
+// solveCrosswordRow and solveCrosswordCol.
+// Returns
+// - if all rows or columns are solved (i.e. complete)
+// - if any row or column was previously unsolved and now solved
+// - an error if the repair is unsuccessful
+func (eds *ExtendedDataSquare) solveCrosswordWave(
+	ctx context.Context,
+	opts options,
+	axis Axis,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	byzAxes *byzantineAxes,
+	stats *repairStats,
+) (bool, bool, error) {
+	decoded, err := eds.decodeCrosswordWave(ctx, opts, axis, rowRoots, colRoots)
+	if err != nil {
+		return false, false, err
+	}
+	checks, err := eds.checkCrosswordWave(ctx, opts, axis, decoded, rowRoots, colRoots)
+	if err != nil {
+		return false, false, err
+	}
+
+	solved := true
+	progressMade := false
+	for i, result := range decoded {
+		if err := ctx.Err(); err != nil {
+			return false, false, err
+		}
+		if result == nil {
+			continue // complete
+		}
+
+		stats.add(&result.stats)
+		err := result.err
+		if result.ok() {
+			if axis == Row {
+				err = eds.insertCrosswordRow(i, colRoots, result.shares, result.rebuiltShares, checks, stats)
+			} else {
+				err = eds.insertCrosswordCol(i, rowRoots, result.shares, result.rebuiltShares, checks, stats)
+			}
+		}
+		if err != nil && !byzAxes.add(err) {
+			return false, false, err
+		}
+		if err != nil || result.rebuiltShares == nil {
+			solved = false
+			continue
+		}
+		progressMade = true
+	}
+	return solved, progressMade, nil
+}
+
+// decodeCrosswordWave decodes all incomplete rows or columns in parallel
+// without modifying the EDS. Complete rows or columns are nil.
+func (eds *ExtendedDataSquare) decodeCrosswordWave(
+	ctx context.Context,
+	opts options,
+	axis Axis,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+) ([]*decodedAxis, error) {
+	decoded := make([]*decodedAxis, eds.axisLen(axis))
+
+	errs, errsCtx := newWorkGroup(ctx, opts)
+	for i := 0; i < len(decoded) && errsCtx.Err() == nil; i++ {
+		i := i
+
+		if noMissingData(eds.axis(axis, uint(i)), noShareInsertion) {
+			continue
+		}
+		result := &decodedAxis{}
+		decoded[i] = result
+		errs.Go(func() error {
+			if err := errsCtx.Err(); err != nil {
+				return err
+			}
+			// Rows and columns share cells, so they are decoded into new
+			// buffers rather than into the storage of the EDS.
+			if axis == Row {
+				result.shares, result.rebuiltShares, result.err = eds.decodeCrosswordRow(i, rowRoots, false, &result.stats)
+			} else {
+				result.shares, result.rebuiltShares, result.err = eds.decodeCrosswordCol(i, colRoots, false, &result.stats)
+			}
+			return nil
+		})
+	}
+	if err := errs.Wait(); err != nil {
+		return nil, err
+	}
+	// the group's context is canceled once Wait returns, so the parent
+	// context has to be checked explicitly
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// checkCrosswordWave checks the orthogonal columns or rows that are completed
+// by inserting the decoded rows or columns against their roots in parallel.
+// An orthogonal column or row is only checked if all of its missing shares
+// were decoded, in which case it is complete with exactly these shares once
+// the last of them is inserted. The checks of the other columns or rows are
+// nil.
+func (eds *ExtendedDataSquare) checkCrosswordWave(
+	ctx context.Context,
+	opts options,
+	axis Axis,
+	decoded []*decodedAxis,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+) ([]*rootCheck, error) {
+	orthogonal := Col
+	if axis == Col {
+		orthogonal = Row
+	}
+	checks := make([]*rootCheck, eds.axisLen(orthogonal))
+
+	errs, errsCtx := newWorkGroup(ctx, opts)
+	for j := 0; j < len(checks) && errsCtx.Err() == nil; j++ {
+		j := j
+
+		shares := append([][]byte(nil), eds.axis(orthogonal, uint(j))...)
+		completed := false
+		for i, share := range shares {
+			if share != nil {
+				continue
+			}
+			if !decoded[i].ok() {
+				completed = false
+				break
+			}
+			shares[i] = decoded[i].rebuiltShares[j]
+			completed = true
+		}
+		if !completed {
+			continue
+		}
+		check := &rootCheck{}
+		checks[j] = check
+		errs.Go(func() error {
+			if err := errsCtx.Err(); err != nil {
+				return err
+			}
+			if orthogonal == Row {
+				check.err = eds.verifyAgainstRowRoots(rowRoots, uint(j), shares, noShareInsertion, nil, nil)
+			} else {
+				check.err = eds.verifyAgainstColRoots(colRoots, uint(j), shares, noShareInsertion, nil, nil)
+			}
+			return nil
+		})
+	}
+	if err := errs.Wait(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return checks, nil
 }
 
 // solveCrosswordRow attempts to repair a single row.
@@ -130,12 +528,42 @@ func (eds *ExtendedDataSquare) solveCrosswordRow(
 	r int,
 	rowRoots [][]byte,
 	colRoots [][]byte,
+	stats *repairStats,
 ) (bool, bool, error) {
 	isComplete := noMissingData(eds.row(uint(r)), noShareInsertion)
 	if isComplete {
 		return true, false, nil
 	}
 
+	shares, rebuiltShares, err := eds.decodeCrosswordRow(r, rowRoots, true, stats)
+	if err != nil {
+		return false, false, err
+	}
+	if rebuiltShares == nil {
+		return false, false, nil
+	}
+
+	err = eds.insertCrosswordRow(r, colRoots, shares, rebuiltShares, nil, stats)
+	if err != nil {
+		return false, false, err
+	}
+	return true, true, nil
+}
+
+// decodeCrosswordRow attempts to rebuild a single incomplete row and checks
+// it against its root. It does not modify the shares of the EDS. If inPlace
+// is true, the missing shares may be rebuilt into the storage of the EDS, see
+// rebuildShares.
+// Returns
+// - the shares of the row prior to repair, with missing shares as nil
+// - the rebuilt shares, or nil if the row could not be decoded
+// - an error if the rebuilt row does not match its root
+func (eds *ExtendedDataSquare) decodeCrosswordRow(
+	r int,
+	rowRoots [][]byte,
+	inPlace bool,
+	stats *repairStats,
+) ([][]byte, [][]byte, error) {
 	// Prepare shares
 	shares := make([][]byte, eds.width)
 	vectorData := eds.row(uint(r))
@@ -144,24 +572,39 @@ func (eds *ExtendedDataSquare) solveCrosswordRow(
 	}
 
 	// Attempt rebuild
-	rebuiltShares, isDecoded, err := eds.rebuildShares(shares)
+	rebuiltShares, isDecoded, err := eds.rebuildShares(Row, uint(r), shares, inPlace, stats)
 	if err != nil {
-		return false, false, err
+		return nil, nil, err
 	}
 	if !isDecoded {
-		return false, false, nil
+		return shares, nil, nil
 	}
 
 	// Check that rebuilt shares matches appropriate root
-	err = eds.verifyAgainstRowRoots(rowRoots, uint(r), rebuiltShares, noShareInsertion, nil)
+	err = eds.verifyAgainstRowRoots(rowRoots, uint(r), rebuiltShares, noShareInsertion, nil, stats)
 	if err != nil {
 		var byzErr *ErrByzantineData
 		if errors.As(err, &byzErr) {
 			byzErr.Shares = shares
 		}
-		return false, false, err
+		return nil, nil, err
 	}
 
+	return shares, rebuiltShares, nil
+}
+
+// insertCrosswordRow checks that the columns completed by a rebuilt row match
+// their roots and inserts the rebuilt shares into the EDS. If checks is not
+// nil, the columns with a check use its result instead of computing their
+// roots.
+func (eds *ExtendedDataSquare) insertCrosswordRow(
+	r int,
+	colRoots [][]byte,
+	shares [][]byte,
+	rebuiltShares [][]byte,
+	checks []*rootCheck,
+	stats *repairStats,
+) error {
 	// Check that newly completed orthogonal vectors match their new merkle roots
 	for c := 0; c < int(eds.width); c++ {
 		col := eds.col(uint(c))
@@ -169,29 +612,38 @@ func (eds *ExtendedDataSquare) solveCrosswordRow(
 			continue // not newly completed
 		}
 		if noMissingData(col, r) { // not completed
-			err := eds.verifyAgainstColRoots(colRoots, uint(c), col, r, rebuiltShares[c])
+			var err error
+			if checks != nil && checks[c] != nil {
+				stats.rootComputed()
+				err = checks[c].err
+			} else {
+				err = eds.verifyAgainstColRoots(colRoots, uint(c), col, r, rebuiltShares[c], stats)
+			}
 			if err != nil {
 				var byzErr *ErrByzantineData
 				if errors.As(err, &byzErr) {
 					byzErr.Shares = shares
 				}
-				return false, false, err
+				return err
 			}
 		}
 	}
 
 	// Insert rebuilt shares into square.
+	sharesFilled := 0
 	for c, s := range rebuiltShares {
 		cellToSet := eds.GetCell(uint(r), uint(c))
 		if cellToSet == nil {
 			err := eds.SetCell(uint(r), uint(c), s)
 			if err != nil {
-				return false, false, err
+				return err
 			}
+			sharesFilled++
 		}
 	}
+	stats.repaired(Row, uint(r), sharesFilled)
 
-	return true, true, nil
+	return nil
 }
 
 // solveCrosswordCol attempts to repair a single column.
@@ -203,79 +655,151 @@ func (eds *ExtendedDataSquare) solveCrosswordCol(
 	c int,
 	rowRoots [][]byte,
 	colRoots [][]byte,
+	stats *repairStats,
 ) (bool, bool, error) {
 	isComplete := noMissingData(eds.col(uint(c)), noShareInsertion)
 	if isComplete {
 		return true, false, nil
 	}
 
+	shares, rebuiltShares, err := eds.decodeCrosswordCol(c, colRoots, true, stats)
+	if err != nil {
+		return false, false, err
+	}
+	if rebuiltShares == nil {
+		return false, false, nil
+	}
+
+	err = eds.insertCrosswordCol(c, rowRoots, shares, rebuiltShares, nil, stats)
+	if err != nil {
+		return false, false, err
+	}
+	return true, true, nil
+}
+
+// decodeCrosswordCol attempts to rebuild a single incomplete column and checks
+// it against its root. It does not modify the shares of the EDS. If inPlace
+// is true, the missing shares may be rebuilt into the storage of the EDS, see
+// rebuildShares.
+// Returns
+// - the shares of the column prior to repair, with missing shares as nil
+// - the rebuilt shares, or nil if the column could not be decoded
+// - an error if the rebuilt column does not match its root
+func (eds *ExtendedDataSquare) decodeCrosswordCol(
+	c int,
+	colRoots [][]byte,
+	inPlace bool,
+	stats *repairStats,
+) ([][]byte, [][]byte, error) {
 	// Prepare shares
-	shares := make([][]byte, eds.width)
+	shares := make([][]byte, eds.height)
 	vectorData := eds.col(uint(c))
-	for r := 0; r < int(eds.width); r++ {
+	for r := 0; r < int(eds.height); r++ {
 		shares[r] = vectorData[r]
 	}
 
 	// Attempt rebuild
-	rebuiltShares, isDecoded, err := eds.rebuildShares(shares)
+	rebuiltShares, isDecoded, err := eds.rebuildShares(Col, uint(c), shares, inPlace, stats)
 	if err != nil {
-		return false, false, err
+		return nil, nil, err
 	}
 	if !isDecoded {
-		return false, false, nil
+		return shares, nil, nil
 	}
 
 	// Check that rebuilt shares matches appropriate root
-	err = eds.verifyAgainstColRoots(colRoots, uint(c), rebuiltShares, noShareInsertion, nil)
+	err = eds.verifyAgainstColRoots(colRoots, uint(c), rebuiltShares, noShareInsertion, nil, stats)
 	if err != nil {
 		var byzErr *ErrByzantineData
 		if errors.As(err, &byzErr) {
 			byzErr.Shares = shares
 		}
-		return false, false, err
+		return nil, nil, err
 	}
 
+	return shares, rebuiltShares, nil
+}
+
+// insertCrosswordCol checks that the rows completed by a rebuilt column match
+// their roots and inserts the rebuilt shares into the EDS. If checks is not
+// nil, the rows with a check use its result instead of computing their roots.
+func (eds *ExtendedDataSquare) insertCrosswordCol(
+	c int,
+	rowRoots [][]byte,
+	shares [][]byte,
+	rebuiltShares [][]byte,
+	checks []*rootCheck,
+	stats *repairStats,
+) error {
 	// Check that newly completed orthogonal vectors match their new merkle roots
-	for r := 0; r < int(eds.width); r++ {
+	for r := 0; r < int(eds.height); r++ {
 		row := eds.row(uint(r))
 		if row[c] != nil {
 			continue // not newly completed
 		}
 		if noMissingData(row, c) { // not completed
-			err := eds.verifyAgainstRowRoots(rowRoots, uint(r), row, c, rebuiltShares[r])
+			var err error
+			if checks != nil && checks[r] != nil {
+				stats.rootComputed()
+				err = checks[r].err
+			} else {
+				err = eds.verifyAgainstRowRoots(rowRoots, uint(r), row, c, rebuiltShares[r], stats)
+			}
 			if err != nil {
 				var byzErr *ErrByzantineData
 				if errors.As(err, &byzErr) {
 					byzErr.Shares = shares
 				}
-				return false, false, err
+				return err
 			}
 		}
 	}
 
 	// Insert rebuilt shares into square.
+	sharesFilled := 0
 	for r, s := range rebuiltShares {
 		cellToSet := eds.GetCell(uint(r), uint(c))
 		if cellToSet == nil {
 			err := eds.SetCell(uint(r), uint(c), s)
 			if err != nil {
-				return false, false, err
+				return err
 			}
+			sharesFilled++
 		}
 	}
+	stats.repaired(Col, uint(c), sharesFilled)
 
-	return true, true, nil
+	return nil
 }
 
-// rebuildShares attempts to rebuild a row or column of shares.
+// rebuildShares attempts to rebuild a row or column of shares. The shares
+// parameter is not modified, so that it can be reported in an ErrByzantineData
+// with missing shares as nil. If inPlace is true and the EDS has storage, the
+// missing shares are rebuilt into the storage of the EDS, where SetCell keeps
+// them without copying. This is only safe if no other row or column is
+// rebuilt at the same time, since a row and a column share a cell. Otherwise
+// the missing shares are rebuilt into new buffers and copied into storage by
+// SetCell once they are verified.
 // Returns
 // 1. An entire row or column of shares so original + parity shares.
 // 2. Whether the original shares could be decoded from the shares parameter.
 // 3. [Optional] an error.
 func (eds *ExtendedDataSquare) rebuildShares(
+	axis Axis,
+	index uint,
 	shares [][]byte,
+	inPlace bool,
+	stats *repairStats,
 ) ([][]byte, bool, error) {
-	rebuiltShares, err := eds.codec.Decode(shares)
+	codec := eds.codecFor(axis)
+	rebuiltShares := append([][]byte(nil), shares...)
+	var err error
+	if dec, ok := codec.(DecodeIntoCodec); ok && inPlace && eds.buf != nil {
+		err = dec.DecodeInto(rebuiltShares, eds.axisBuffers(axis, index))
+	} else {
+		rebuiltShares, err = codec.Decode(rebuiltShares)
+	}
+	stats.decoded(err == nil)
 	if err != nil {
 		// Decode was unsuccessful but don't propagate the error because that
 		// would halt the progress of solveCrosswordRow or solveCrosswordCol.
@@ -291,7 +815,9 @@ func (eds *ExtendedDataSquare) verifyAgainstRowRoots(
 	oldShares [][]byte,
 	rebuiltIndex int,
 	rebuiltShare []byte,
+	stats *repairStats,
 ) error {
+	stats.rootComputed()
 	var root []byte
 	var err error
 	if rebuiltIndex < 0 || rebuiltShare == nil {
@@ -300,11 +826,13 @@ func (eds *ExtendedDataSquare) verifyAgainstRowRoots(
 		root, err = eds.computeSharesRootWithRebuiltShare(oldShares, Row, r, rebuiltIndex, rebuiltShare)
 	}
 	if err != nil {
-		return err
+		// shares that the tree rejects, e.g. shares whose namespaces are out
+		// of order, cannot match any root
+		return &ErrByzantineData{Axis: Row, Index: r}
 	}
 
 	if !bytes.Equal(root, rowRoots[r]) {
-		return &ErrByzantineData{Row, r, nil}
+		return &ErrByzantineData{Axis: Row, Index: r}
 	}
 
 	return nil
@@ -316,7 +844,9 @@ func (eds *ExtendedDataSquare) verifyAgainstColRoots(
 	oldShares [][]byte,
 	rebuiltIndex int,
 	rebuiltShare []byte,
+	stats *repairStats,
 ) error {
+	stats.rootComputed()
 	var root []byte
 	var err error
 	if rebuiltIndex < 0 || rebuiltShare == nil {
@@ -325,79 +855,135 @@ func (eds *ExtendedDataSquare) verifyAgainstColRoots(
 		root, err = eds.computeSharesRootWithRebuiltShare(oldShares, Col, c, rebuiltIndex, rebuiltShare)
 	}
 	if err != nil {
-		return err
+		// shares that the tree rejects, e.g. shares whose namespaces are out
+		// of order, cannot match any root
+		return &ErrByzantineData{Axis: Col, Index: c}
 	}
 
 	if !bytes.Equal(root, colRoots[c]) {
-		return &ErrByzantineData{Col, c, nil}
+		return &ErrByzantineData{Axis: Col, Index: c}
 	}
 
 	return nil
 }
 
+// prerepairSanityCheck checks that complete rows and columns match their roots
+// and that their parity shares are the encoding of their original shares. The
+// error of the row with the lowest index is returned, or, if all rows are
+// valid, the error of the column with the lowest index. If byzAxes is not nil,
+// ErrByzantineData are recorded in byzAxes instead of being returned. If
+// rowRoots and colRoots are nil, only the parity shares are checked.
 func (eds *ExtendedDataSquare) prerepairSanityCheck(
+	ctx context.Context,
+	opts options,
 	rowRoots [][]byte,
 	colRoots [][]byte,
+	byzAxes *byzantineAxes,
+	stats *repairStats,
 ) error {
-	errs, _ := errgroup.WithContext(context.Background())
+	// the errors of the root and parity checks of every row and column
+	rowErrs := make([][2]error, eds.height)
+	colErrs := make([][2]error, eds.width)
+
+	errs, errsCtx := newWorkGroup(ctx, opts)
 
-	for i := uint(0); i < eds.width; i++ {
+	for i := uint(0); i < eds.height && errsCtx.Err() == nil; i++ {
 		i := i
 
 		rowIsComplete := noMissingData(eds.row(i), noShareInsertion)
 		// if there's no missing data in this row
 		if rowIsComplete {
 			errs.Go(func() error {
+				if err := errsCtx.Err(); err != nil {
+					return err
+				}
+				if rowRoots == nil {
+					return nil
+				}
 				// ensure that the roots are equal
+				if eds.rowRoots == nil {
+					stats.rootComputed()
+				}
 				rowRoot, err := eds.getRowRoot(i)
 				if err != nil {
-					return err
-				}
-				if !bytes.Equal(rowRoots[i], rowRoot) {
-					return fmt.Errorf("bad root input: row %d expected %v got %v", i, rowRoots[i], rowRoot)
+					// the tree rejects the shares of the row
+					rowErrs[i][0] = &ErrByzantineData{Axis: Row, Index: i, Shares: eds.row(i)}
+				} else if !bytes.Equal(rowRoots[i], rowRoot) {
+					rowErrs[i][0] = &ErrRootMismatch{Row, i, rowRoots[i], rowRoot}
 				}
 				return nil
 			})
 			errs.Go(func() error {
-				parityShares, err := eds.codec.Encode(eds.rowSlice(i, 0, eds.originalDataWidth))
-				if err != nil {
+				if err := errsCtx.Err(); err != nil {
 					return err
 				}
-				if !bytes.Equal(flattenChunks(parityShares), flattenChunks(eds.rowSlice(i, eds.originalDataWidth, eds.originalDataWidth))) {
-					return &ErrByzantineData{Row, i, eds.row(i)}
+				parityShares, err := eds.codec.Encode(eds.rowSlice(i, 0, eds.originalDataWidth))
+				if err != nil {
+					rowErrs[i][1] = err
+				} else if !bytes.Equal(flattenChunks(parityShares), flattenChunks(eds.rowSlice(i, eds.originalDataWidth, eds.width-eds.originalDataWidth))) {
+					rowErrs[i][1] = &ErrByzantineData{Axis: Row, Index: i, Shares: eds.row(i)}
 				}
 				return nil
 			})
 		}
+	}
+
+	for i := uint(0); i < eds.width && errsCtx.Err() == nil; i++ {
+		i := i
 
 		colIsComplete := noMissingData(eds.col(i), noShareInsertion)
 		// if there's no missing data in this col
 		if colIsComplete {
 			errs.Go(func() error {
+				if err := errsCtx.Err(); err != nil {
+					return err
+				}
+				if colRoots == nil {
+					return nil
+				}
 				// ensure that the roots are equal
+				if eds.colRoots == nil {
+					stats.rootComputed()
+				}
 				colRoot, err := eds.getColRoot(i)
 				if err != nil {
-					return err
-				}
-				if !bytes.Equal(colRoots[i], colRoot) {
-					return fmt.Errorf("bad root input: col %d expected %v got %v", i, colRoots[i], colRoot)
+					// the tree rejects the shares of the column
+					colErrs[i][0] = &ErrByzantineData{Axis: Col, Index: i, Shares: eds.col(i)}
+				} else if !bytes.Equal(colRoots[i], colRoot) {
+					colErrs[i][0] = &ErrRootMismatch{Col, i, colRoots[i], colRoot}
 				}
 				return nil
 			})
 			errs.Go(func() error {
-				parityShares, err := eds.codec.Encode(eds.colSlice(0, i, eds.originalDataWidth))
-				if err != nil {
+				if err := errsCtx.Err(); err != nil {
 					return err
 				}
-				if !bytes.Equal(flattenChunks(parityShares), flattenChunks(eds.colSlice(eds.originalDataWidth, i, eds.originalDataWidth))) {
-					return &ErrByzantineData{Col, i, eds.col(i)}
+				parityShares, err := eds.colCodec.Encode(eds.colSlice(0, i, eds.originalDataHeight))
+				if err != nil {
+					colErrs[i][1] = err
+				} else if !bytes.Equal(flattenChunks(parityShares), flattenChunks(eds.colSlice(eds.originalDataHeight, i, eds.height-eds.originalDataHeight))) {
+					colErrs[i][1] = &ErrByzantineData{Axis: Col, Index: i, Shares: eds.col(i)}
 				}
 				return nil
 			})
 		}
 	}
 
-	return errs.Wait()
+	if err := errs.Wait(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, axisErrs := range append(rowErrs, colErrs...) {
+		for _, err := range axisErrs {
+			if err != nil && !byzAxes.add(err) {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func noMissingData(input [][]byte, rebuiltIndex int) bool {
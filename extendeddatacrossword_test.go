@@ -2,6 +2,7 @@ package rsmt2d
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -11,14 +12,6 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-// PseudoFraudProof is an example fraud proof.
-// TODO a real fraud proof would have a Merkle proof for each share.
-type PseudoFraudProof struct {
-	Mode   int      // Row (0) or column (1)
-	Index  uint     // Row or column index
-	Shares [][]byte // Bad shares (nil are missing)
-}
-
 func TestRepairExtendedDataSquare(t *testing.T) {
 	bufferSize := 64
 	tests := []struct {
@@ -28,6 +21,7 @@ func TestRepairExtendedDataSquare(t *testing.T) {
 		codec     Codec
 	}{
 		{"leopard", bufferSize, NewLeoRSCodec()},
+		{"rsgf8", bufferSize, NewRSGF8Codec()},
 	}
 
 	for _, test := range tests {
@@ -89,6 +83,334 @@ func TestRepairExtendedDataSquare(t *testing.T) {
 	}
 }
 
+func TestRepairExtendedDataSquareExtensionFactor(t *testing.T) {
+	shareSize := 64
+	for _, codec := range []Codec{
+		NewLeoRSCodec(WithExtensionFactor(4)),
+		NewRSGF8Codec(WithExtensionFactor(4)),
+	} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			original := createTestEds(codec, shareSize)
+			require.Equal(t, uint(8), original.Width())
+
+			rowRoots, err := original.RowRoots()
+			require.NoError(t, err)
+			colRoots, err := original.ColRoots()
+			require.NoError(t, err)
+
+			// Keep only the shares of the last originalDataWidth columns, i.e.
+			// a quarter of the square.
+			keepFrom := original.Width() - original.originalDataWidth
+			flattened := original.Flattened()
+			for r := uint(0); r < original.Width(); r++ {
+				for c := uint(0); c < keepFrom; c++ {
+					flattened[r*original.Width()+c] = nil
+				}
+			}
+
+			eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+			require.NoError(t, err)
+			require.NoError(t, eds.Repair(rowRoots, colRoots))
+			assert.Equal(t, original.Flattened(), eds.Flattened())
+
+			// Keeping only the last row and column leaves every other row and
+			// column with a single share, which is not enough to repair it.
+			flattened = original.Flattened()
+			for r := uint(0); r < original.Width()-1; r++ {
+				for c := uint(0); c < original.Width()-1; c++ {
+					flattened[r*original.Width()+c] = nil
+				}
+			}
+			eds, err = ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+			require.NoError(t, err)
+			assert.ErrorIs(t, eds.Repair(rowRoots, colRoots), ErrUnrepairableDataSquare)
+		})
+	}
+}
+
+func TestRepairExtendedDataRectangle(t *testing.T) {
+	shareSize := 64
+	data := make([][]byte, 6)
+	for i := range data {
+		data[i] = bytes.Repeat([]byte{byte(i + 1)}, shareSize)
+	}
+	// 2 rows of 3 shares, with rows extended by 2x and columns by 4x.
+	original, err := ComputeExtendedDataRectangle(data, 2, NewLeoRSCodec(), NewLeoRSCodec(WithExtensionFactor(4)), NewDefaultTree)
+	require.NoError(t, err)
+
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+
+	// Keep only the last originalDataHeight rows, which is enough to decode
+	// every column.
+	flattened := original.Flattened()
+	for r := uint(0); r < original.Height()-original.originalDataHeight; r++ {
+		for c := uint(0); c < original.Width(); c++ {
+			flattened[r*original.Width()+c] = nil
+		}
+	}
+
+	eds, err := ImportExtendedDataRectangle(flattened, original.Height(), original.codec, original.colCodec, NewDefaultTree)
+	require.NoError(t, err)
+	require.NoError(t, eds.Repair(rowRoots, colRoots))
+	assert.Equal(t, original.Flattened(), eds.Flattened())
+
+	t.Run("mismatched roots", func(t *testing.T) {
+		eds, err := ImportExtendedDataRectangle(flattened, original.Height(), original.codec, original.colCodec, NewDefaultTree)
+		require.NoError(t, err)
+		assert.Error(t, eds.Repair(colRoots, rowRoots))
+	})
+}
+
+// cancelingCodec cancels a context after the first successful decode.
+type cancelingCodec struct {
+	Codec
+	cancel context.CancelFunc
+}
+
+func (c *cancelingCodec) Decode(data [][]byte) ([][]byte, error) {
+	decoded, err := c.Codec.Decode(data)
+	if err == nil {
+		c.cancel()
+	}
+	return decoded, err
+}
+
+func TestRepairWithContext(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+
+	// Remove the original data square, so that every row has to be decoded.
+	flattened := original.Flattened()
+	for r := uint(0); r < original.originalDataWidth; r++ {
+		for c := uint(0); c < original.originalDataWidth; c++ {
+			flattened[r*original.Width()+c] = nil
+		}
+	}
+
+	t.Run("canceled before repair", func(t *testing.T) {
+		eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, eds.RepairWithContext(ctx, rowRoots, colRoots), context.Canceled)
+		assert.Equal(t, flattened, eds.Flattened())
+	})
+
+	t.Run("canceled during repair can be resumed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		eds, err := ImportExtendedDataSquare(flattened, &cancelingCodec{codec, cancel}, NewDefaultTree)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, eds.RepairWithContext(ctx, rowRoots, colRoots), context.Canceled)
+		assert.NotEqual(t, original.Flattened(), eds.Flattened())
+
+		require.NoError(t, eds.Repair(rowRoots, colRoots))
+		assert.Equal(t, original.Flattened(), eds.Flattened())
+	})
+}
+
+// solvers are the options that select each crossword solver.
+var solvers = map[string][]Option{
+	"sequential": nil,
+	"parallel":   {WithParallelSolver()},
+}
+
+func TestRepairParallelSolver(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original, err := ComputeExtendedDataSquare(genRandDS(8), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+
+	width := int(original.Width())
+	rng := rand.New(rand.NewSource(1))
+	for _, removed := range []int{width * width / 4, width * width / 2, width * width * 3 / 4} {
+		for n := 0; n < 10; n++ {
+			flattened := original.Flattened()
+			for _, i := range rng.Perm(width * width)[:removed] {
+				flattened[i] = nil
+			}
+
+			sequential, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+			require.NoError(t, err)
+			wantErr := sequential.Repair(rowRoots, colRoots)
+
+			parallel, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree, WithMaxConcurrency(3))
+			require.NoError(t, err)
+			gotErr := parallel.Repair(rowRoots, colRoots, WithParallelSolver())
+
+			assert.Equal(t, wantErr, gotErr)
+			// The shares that can be repaired don't depend on the order in
+			// which rows and columns are repaired.
+			assert.Equal(t, sequential.Flattened(), parallel.Flattened())
+		}
+	}
+
+	t.Run("byzantine", func(t *testing.T) {
+		corrupted, err := original.deepCopy()
+		require.NoError(t, err)
+		corrupted.setCell(1, 0, bytes.Repeat([]byte{66}, len(corrupted.GetCell(1, 0))))
+		// Remove shares so that rows 1 and 2 have to be decoded, and the
+		// sanity check doesn't find the corrupted share.
+		for c := uint(1); c < corrupted.Width(); c += 2 {
+			corrupted.setCell(1, c, nil)
+			corrupted.setCell(2, c, nil)
+		}
+		for r := uint(3); r < corrupted.Height(); r++ {
+			corrupted.setCell(r, 0, nil)
+		}
+		shares := corrupted.Row(1)
+		for c := uint(1); c < corrupted.Width(); c += 2 {
+			shares[c] = nil
+		}
+
+		err = corrupted.Repair(rowRoots, colRoots, WithParallelSolver())
+		var byzData *ErrByzantineData
+		require.ErrorAs(t, err, &byzData)
+		assert.Equal(t, Row, byzData.Axis)
+		assert.Equal(t, uint(1), byzData.Index)
+		assert.Equal(t, shares, byzData.Shares)
+	})
+}
+
+// repairInWaves repairs eds in the order of the parallel solver, solving one
+// row or column at a time. It is the reference for the parallel solver.
+func repairInWaves(eds *ExtendedDataSquare, rowRoots [][]byte, colRoots [][]byte, collect bool) (RepairStats, error) {
+	var byzAxes *byzantineAxes
+	if collect {
+		byzAxes = &byzantineAxes{}
+	}
+	stats := &repairStats{}
+	solve := func() error {
+		for {
+			stats.startIteration()
+			solved, progressMade := true, false
+			for _, axis := range []Axis{Row, Col} {
+				for i := 0; i < int(eds.axisLen(axis)); i++ {
+					solveAxis := eds.solveCrosswordRow
+					if axis == Col {
+						solveAxis = eds.solveCrosswordCol
+					}
+					solvedAxis, progressMadeAxis, err := solveAxis(i, rowRoots, colRoots, stats)
+					if err != nil && !byzAxes.add(err) {
+						return err
+					}
+					solved = solved && solvedAxis
+					progressMade = progressMade || progressMadeAxis
+				}
+			}
+			if solved {
+				return byzAxes.err()
+			}
+			if !progressMade {
+				if err := byzAxes.err(); err != nil {
+					return err
+				}
+				return ErrUnrepairableDataSquare
+			}
+		}
+	}
+
+	err := eds.prerepairSanityCheck(context.Background(), eds.opts, rowRoots, colRoots, byzAxes, stats)
+	if err == nil {
+		err = solve()
+	}
+	return stats.summary(), eds.attachBadEncodingProofs(err)
+}
+
+func TestRepairParallelSolverByzantine(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+
+	type cell struct{ row, col uint }
+	// corrupted corrupts and removes shares of a copy of the original
+	// square, kept in storage if storage is true.
+	corrupted := func(corrupt cell, missing []cell, storage bool) *ExtendedDataSquare {
+		flattened := original.Flattened()
+		width := original.Width()
+		flattened[corrupt.row*width+corrupt.col] = bytes.Repeat([]byte{66}, len(flattened[0]))
+		for _, c := range missing {
+			flattened[c.row*width+c.col] = nil
+		}
+		var opts []Option
+		if storage {
+			opts = append(opts, WithStorage(make(byteStorage, len(flattened)*len(original.GetCell(0, 0)))))
+		}
+		eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree, opts...)
+		require.NoError(t, err)
+		return eds
+	}
+	assertSameRepair := func(t *testing.T, corrupt cell, missing []cell, storage bool, collect bool) {
+		want := corrupted(corrupt, missing, storage)
+		wantStats, wantErr := repairInWaves(want, rowRoots, colRoots, collect)
+
+		opts := []Option{WithParallelSolver(), WithMaxConcurrency(3)}
+		if collect {
+			opts = append(opts, WithCollectByzantineData())
+		}
+		got := corrupted(corrupt, missing, storage)
+		gotStats, gotErr := got.RepairWithStats(context.Background(), rowRoots, colRoots, opts...)
+		gotStats.SanityCheckDuration, gotStats.SolveDuration = 0, 0
+
+		assert.Equal(t, wantErr, gotErr)
+		assert.Equal(t, wantStats, gotStats)
+		assert.Equal(t, want.Flattened(), got.Flattened())
+	}
+
+	t.Run("rows before columns", func(t *testing.T) {
+		corrupt, missing := cell{1, 0}, []cell{{1, 5}, {6, 0}}
+		// the sequential solver interleaves rows and columns and reports
+		// column 0, which is solved before row 1
+		err := corrupted(corrupt, missing, false).Repair(rowRoots, colRoots)
+		var byzData *ErrByzantineData
+		require.ErrorAs(t, err, &byzData)
+		assert.Equal(t, Col, byzData.Axis)
+		assert.Equal(t, uint(0), byzData.Index)
+
+		err = corrupted(corrupt, missing, false).Repair(rowRoots, colRoots, WithParallelSolver())
+		require.ErrorAs(t, err, &byzData)
+		assert.Equal(t, Row, byzData.Axis)
+		assert.Equal(t, uint(1), byzData.Index)
+		assertSameRepair(t, corrupt, missing, false, false)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		width := int(original.Width())
+		rng := rand.New(rand.NewSource(2))
+		for n := 0; n < 100; n++ {
+			perm := rng.Perm(width * width)
+			corrupt := cell{uint(perm[0] / width), uint(perm[0] % width)}
+			missing := make([]cell, 1+rng.Intn(width*width/2))
+			for i := range missing {
+				missing[i] = cell{uint(perm[i+1] / width), uint(perm[i+1] % width)}
+			}
+			assertSameRepair(t, corrupt, missing, false, false)
+			assertSameRepair(t, corrupt, missing, false, true)
+			// decoding must not overwrite shares in storage that were
+			// verified by another row or column
+			assertSameRepair(t, corrupt, missing, true, false)
+			assertSameRepair(t, corrupt, missing, true, true)
+		}
+	})
+}
+
 func TestValidFraudProof(t *testing.T) {
 	bufferSize := 64
 	corruptChunk := bytes.Repeat([]byte{66}, bufferSize)
@@ -99,6 +421,7 @@ func TestValidFraudProof(t *testing.T) {
 		codec     Codec
 	}{
 		{"leopard", bufferSize, NewLeoRSCodec()},
+		{"rsgf8", bufferSize, NewRSGF8Codec()},
 	}
 
 	for _, test := range tests {
@@ -107,7 +430,7 @@ func TestValidFraudProof(t *testing.T) {
 			original := createTestEds(codec, shareSize)
 
 			var byzData *ErrByzantineData
-			corrupted, err := original.deepCopy(codec)
+			corrupted, err := original.deepCopy()
 			if err != nil {
 				t.Fatalf("unexpected err while copying original data: %v, codec: :%s", err, name)
 			}
@@ -121,31 +444,15 @@ func TestValidFraudProof(t *testing.T) {
 			assert.NoError(t, err)
 
 			err = corrupted.Repair(rowRoots, colRoots)
-			errors.As(err, &byzData)
+			require.ErrorAs(t, err, &byzData)
 
 			// Construct the fraud proof
-			fraudProof := PseudoFraudProof{0, byzData.Index, byzData.Shares}
-			// Verify the fraud proof
-			// TODO in a real fraud proof, also verify Merkle proof for each non-nil share.
-			rebuiltShares, err := codec.Decode(fraudProof.Shares)
-			if err != nil {
-				t.Errorf("could not decode fraud proof shares; got: %v", err)
-			}
-			root, err := corrupted.computeSharesRoot(rebuiltShares, byzData.Axis, fraudProof.Index)
-			assert.NoError(t, err)
-			rowRoot, err := corrupted.getRowRoot(fraudProof.Index)
-			assert.NoError(t, err)
-			if bytes.Equal(root, rowRoot) {
-				// If the roots match, then the fraud proof should be for invalid erasure coding.
-				parityShares, err := codec.Encode(rebuiltShares[0:corrupted.originalDataWidth])
-				if err != nil {
-					t.Errorf("could not encode fraud proof shares; %v", fraudProof)
-				}
-				startIndex := len(rebuiltShares) - int(corrupted.originalDataWidth)
-				if bytes.Equal(flattenChunks(parityShares), flattenChunks(rebuiltShares[startIndex:])) {
-					t.Errorf("invalid fraud proof %v", fraudProof)
-				}
-			}
+			proof, err := corrupted.ProveBadEncoding(byzData)
+			require.NoError(t, err)
+			assert.Equal(t, proof, byzData.Proof)
+			// Verify the fraud proof, including the inclusion proof of every
+			// share
+			assert.NoError(t, proof.Verify(rowRoots, colRoots, codec, NewDefaultTree))
 		})
 	}
 }
@@ -160,6 +467,7 @@ func TestCannotRepairSquareWithBadRoots(t *testing.T) {
 		codec     Codec
 	}{
 		{"leopard", bufferSize, NewLeoRSCodec()},
+		{"rsgf8", bufferSize, NewRSGF8Codec()},
 	}
 
 	for _, test := range tests {
@@ -234,26 +542,30 @@ func TestCorruptedEdsReturnsErrByzantineData(t *testing.T) {
 		t.Run(codecName, func(t *testing.T) {
 			for _, test := range tests {
 				t.Run(test.name, func(t *testing.T) {
-					eds := createTestEds(codec, shareSize)
-					for i, coords := range test.coords {
-						x := coords[0]
-						y := coords[1]
-						eds.setCell(x, y, test.values[i])
-					}
-					rowRoots, err := eds.getRowRoots()
-					assert.NoError(t, err)
+					for solverName, opts := range solvers {
+						t.Run(solverName, func(t *testing.T) {
+							eds := createTestEds(codec, shareSize)
+							for i, coords := range test.coords {
+								x := coords[0]
+								y := coords[1]
+								eds.setCell(x, y, test.values[i])
+							}
+							rowRoots, err := eds.getRowRoots()
+							assert.NoError(t, err)
 
-					colRoots, err := eds.getColRoots()
-					assert.NoError(t, err)
+							colRoots, err := eds.getColRoots()
+							assert.NoError(t, err)
 
-					err = eds.Repair(rowRoots, colRoots)
-					assert.Error(t, err)
+							err = eds.Repair(rowRoots, colRoots, opts...)
+							assert.Error(t, err)
 
-					// due to parallelisation, the ErrByzantineData axis may be either row or col
-					var byzData *ErrByzantineData
-					assert.ErrorAs(t, err, &byzData, "did not return a ErrByzantineData for a bad col or row")
-					assert.NotEmpty(t, byzData.Shares)
-					assert.Contains(t, byzData.Shares, corruptChunk)
+							// due to parallelisation, the ErrByzantineData axis may be either row or col
+							var byzData *ErrByzantineData
+							assert.ErrorAs(t, err, &byzData, "did not return a ErrByzantineData for a bad col or row")
+							assert.NotEmpty(t, byzData.Shares)
+							assert.Contains(t, byzData.Shares, corruptChunk)
+						})
+					}
 				})
 			}
 		})
@@ -283,46 +595,50 @@ func BenchmarkRepair(b *testing.B) {
 			colRoots, err := eds.ColRoots()
 			assert.NoError(b, err)
 
-			b.Run(
-				fmt.Sprintf(
-					"%s %dx%dx%d ODS",
-					codecName,
-					originalDataWidth,
-					originalDataWidth,
-					len(square[0]),
-				),
-				func(b *testing.B) {
-					for n := 0; n < b.N; n++ {
-						b.StopTimer()
-
-						flattened := eds.Flattened()
-						// Randomly remove 1/2 of the shares of each row
-						for r := 0; r < extendedDataWidth; r++ {
-							for c := 0; c < originalDataWidth; {
-								ind := rand.Intn(extendedDataWidth)
-								if flattened[r*extendedDataWidth+ind] == nil {
-									continue
+			for solverName, opts := range solvers {
+				b.Run(
+					fmt.Sprintf(
+						"%s %s %dx%dx%d ODS",
+						codecName,
+						solverName,
+						originalDataWidth,
+						originalDataWidth,
+						len(square[0]),
+					),
+					func(b *testing.B) {
+						for n := 0; n < b.N; n++ {
+							b.StopTimer()
+
+							flattened := eds.Flattened()
+							// Randomly remove 1/2 of the shares of each row
+							for r := 0; r < extendedDataWidth; r++ {
+								for c := 0; c < originalDataWidth; {
+									ind := rand.Intn(extendedDataWidth)
+									if flattened[r*extendedDataWidth+ind] == nil {
+										continue
+									}
+									flattened[r*extendedDataWidth+ind] = nil
+									c++
 								}
-								flattened[r*extendedDataWidth+ind] = nil
-								c++
 							}
-						}
 
-						// Re-import the data square.
-						eds, _ = ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+							// Re-import the data square.
+							eds, _ = ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
 
-						b.StartTimer()
+							b.StartTimer()
 
-						err := eds.Repair(
-							rowRoots,
-							colRoots,
-						)
-						if err != nil {
-							b.Error(err)
+							err := eds.Repair(
+								rowRoots,
+								colRoots,
+								opts...,
+							)
+							if err != nil {
+								b.Error(err)
+							}
 						}
-					}
-				},
-			)
+					},
+				)
+			}
 		}
 	}
 }
@@ -343,3 +659,107 @@ func createTestEds(codec Codec, shareSize int) *ExtendedDataSquare {
 
 	return eds
 }
+
+func TestRepairCollectByzantineData(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+	corruptChunk := bytes.Repeat([]byte{66}, len(original.GetCell(0, 0)))
+
+	// corrupt returns a copy of the original square with corrupted shares at
+	// (0, 1) and (5, 6). The diagonal is removed, so that only the solver can
+	// detect the Byzantine data.
+	corrupt := func(t *testing.T) ExtendedDataSquare {
+		eds, err := original.deepCopy()
+		require.NoError(t, err)
+		eds.setCell(0, 1, corruptChunk)
+		eds.setCell(5, 6, corruptChunk)
+		for i := uint(0); i < eds.Width(); i++ {
+			eds.setCell(i, i, nil)
+		}
+		return eds
+	}
+
+	t.Run("sanity check is deterministic", func(t *testing.T) {
+		for n := 0; n < 20; n++ {
+			eds, err := original.deepCopy()
+			require.NoError(t, err)
+			eds.setCell(2, 1, corruptChunk)
+			eds.setCell(6, 3, corruptChunk)
+			corruptedRowRoots, err := eds.getRowRoots()
+			require.NoError(t, err)
+			corruptedColRoots, err := eds.getColRoots()
+			require.NoError(t, err)
+
+			err = eds.Repair(corruptedRowRoots, corruptedColRoots)
+			var byzData *ErrByzantineData
+			require.ErrorAs(t, err, &byzData)
+			assert.Equal(t, Row, byzData.Axis)
+			assert.Equal(t, uint(2), byzData.Index)
+		}
+	})
+
+	t.Run("sanity check", func(t *testing.T) {
+		eds, err := original.deepCopy()
+		require.NoError(t, err)
+		eds.setCell(2, 1, corruptChunk)
+		eds.setCell(6, 3, corruptChunk)
+		corruptedRowRoots, err := eds.getRowRoots()
+		require.NoError(t, err)
+		corruptedColRoots, err := eds.getColRoots()
+		require.NoError(t, err)
+
+		err = eds.Repair(corruptedRowRoots, corruptedColRoots, WithCollectByzantineData())
+		var byzAxes *ErrByzantineAxes
+		require.ErrorAs(t, err, &byzAxes)
+		var got [][2]uint
+		for _, byzData := range byzAxes.Errors {
+			got = append(got, [2]uint{uint(byzData.Axis), byzData.Index})
+		}
+		assert.Equal(t, [][2]uint{{uint(Row), 2}, {uint(Row), 6}, {uint(Col), 1}, {uint(Col), 3}}, got)
+
+		var byzData *ErrByzantineData
+		require.ErrorAs(t, err, &byzData)
+		assert.Equal(t, Row, byzData.Axis)
+		assert.Equal(t, uint(2), byzData.Index)
+	})
+
+	for solverName, opts := range solvers {
+		t.Run(solverName, func(t *testing.T) {
+			eds := corrupt(t)
+			err := eds.Repair(rowRoots, colRoots, append(opts, WithCollectByzantineData())...)
+			var byzAxes *ErrByzantineAxes
+			require.ErrorAs(t, err, &byzAxes)
+
+			var rows []uint
+			for i, byzData := range byzAxes.Errors {
+				if i > 0 {
+					prev := byzAxes.Errors[i-1]
+					assert.True(t, prev.Axis < byzData.Axis || prev.Axis == byzData.Axis && prev.Index < byzData.Index)
+				}
+				if byzData.Axis == Row {
+					rows = append(rows, byzData.Index)
+					assert.Contains(t, byzData.Shares, corruptChunk)
+				}
+			}
+			assert.Equal(t, []uint{0, 5}, rows)
+
+			// Rows that don't intersect Byzantine data at their missing share
+			// are repaired.
+			for _, r := range []uint{2, 3, 4, 7} {
+				assert.Equal(t, flattenChunks(original.Row(r)), flattenChunks(eds.Row(r)))
+			}
+
+			// Without the option, repairing stops at the first Byzantine row.
+			eds = corrupt(t)
+			err = eds.Repair(rowRoots, colRoots, opts...)
+			var byzData *ErrByzantineData
+			require.ErrorAs(t, err, &byzData)
+			assert.False(t, errors.As(err, &byzAxes))
+		})
+	}
+}
@@ -4,39 +4,35 @@ package rsmt2d
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
-
-	"golang.org/x/sync/errgroup"
+	"fmt"
 )
 
-// ExtendedDataSquare represents an extended piece of data.
+// ExtendedDataSquare represents an extended piece of data. It is usually a
+// square, but may also be a rectangle whose rows and columns are extended with
+// different codecs (see ComputeExtendedDataRectangle).
 type ExtendedDataSquare struct {
 	*dataSquare
-	codec             Codec
-	originalDataWidth uint
+	// codec extends and repairs rows.
+	codec Codec
+	// colCodec extends and repairs columns. It is identical to codec for
+	// squares.
+	colCodec           Codec
+	originalDataWidth  uint
+	originalDataHeight uint
 }
 
+// MarshalJSON encodes the square as JSON with the default Encoder, without
+// roots.
 func (eds *ExtendedDataSquare) MarshalJSON() ([]byte, error) {
-	return json.Marshal(&struct {
-		DataSquare [][]byte `json:"data_square"`
-		Codec      string   `json:"codec"`
-	}{
-		DataSquare: eds.dataSquare.Flattened(),
-		Codec:      eds.codec.Name(),
-	})
+	return Encoder{}.EncodeJSON(eds)
 }
 
+// UnmarshalJSON decodes a square encoded as JSON with the default Decoder,
+// using NewDefaultTree as the tree constructor. Use Decoder.DecodeJSON to
+// decode a square with a different tree.
 func (eds *ExtendedDataSquare) UnmarshalJSON(b []byte) error {
-	var aux struct {
-		DataSquare [][]byte `json:"data_square"`
-		Codec      string   `json:"codec"`
-	}
-
-	if err := json.Unmarshal(b, &aux); err != nil {
-		return err
-	}
-	importedEds, err := ImportExtendedDataSquare(aux.DataSquare, codecs[aux.Codec], NewDefaultTree)
+	importedEds, err := Decoder{}.DecodeJSON(b)
 	if err != nil {
 		return err
 	}
@@ -49,7 +45,25 @@ func ComputeExtendedDataSquare(
 	data [][]byte,
 	codec Codec,
 	treeCreatorFn TreeConstructorFn,
+	opts ...Option,
 ) (*ExtendedDataSquare, error) {
+	return ComputeExtendedDataSquareWithContext(context.Background(), data, codec, treeCreatorFn, opts...)
+}
+
+// ComputeExtendedDataSquareWithContext computes the extended data square for
+// some chunks of data. If ctx is done before the square is extended, no
+// further rows or columns are encoded and ctx.Err() is returned without a
+// square. The chunks of data are never modified.
+func ComputeExtendedDataSquareWithContext(
+	ctx context.Context,
+	data [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...Option,
+) (*ExtendedDataSquare, error) {
+	if err := validateExtensionFactor(codec); err != nil {
+		return nil, err
+	}
 	if len(data) > codec.MaxChunks() {
 		return nil, errors.New("number of chunks exceeds the maximum")
 	}
@@ -58,9 +72,68 @@ func ComputeExtendedDataSquare(
 	if err != nil {
 		return nil, err
 	}
+	ds.opts = newOptions(opts)
 
-	eds := ExtendedDataSquare{dataSquare: ds, codec: codec}
-	err = eds.erasureExtendSquare(codec)
+	eds := ExtendedDataSquare{dataSquare: ds, codec: codec, colCodec: codec}
+	err = eds.erasureExtendSquare(ctx, codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eds, nil
+}
+
+// ComputeExtendedDataRectangle computes the extended data for some chunks of
+// data arranged in height rows of equal width. Rows are extended with rowCodec
+// and columns with colCodec, so the number of parity rows and columns follows
+// the extension factor of the respective codec.
+//
+// The parity quadrant is computed by extending the parity rows, which only
+// yields valid columns if both codecs are linear over the same field. Use the
+// same codec with different extension factors, not e.g. Leopard for rows and
+// RSGF8 for columns.
+func ComputeExtendedDataRectangle(
+	data [][]byte,
+	height uint,
+	rowCodec Codec,
+	colCodec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...Option,
+) (*ExtendedDataSquare, error) {
+	return ComputeExtendedDataRectangleWithContext(context.Background(), data, height, rowCodec, colCodec, treeCreatorFn, opts...)
+}
+
+// ComputeExtendedDataRectangleWithContext computes the extended data like
+// ComputeExtendedDataRectangle. If ctx is done before the data is extended,
+// no further rows or columns are encoded and ctx.Err() is returned without a
+// square.
+func ComputeExtendedDataRectangleWithContext(
+	ctx context.Context,
+	data [][]byte,
+	height uint,
+	rowCodec Codec,
+	colCodec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...Option,
+) (*ExtendedDataSquare, error) {
+	if err := validateExtensionFactor(rowCodec); err != nil {
+		return nil, err
+	}
+	if err := validateExtensionFactor(colCodec); err != nil {
+		return nil, err
+	}
+
+	ds, err := newDataRectangle(data, height, treeCreatorFn)
+	if err != nil {
+		return nil, err
+	}
+	ds.opts = newOptions(opts)
+	if int(ds.width*ds.width) > rowCodec.MaxChunks() || int(ds.height*ds.height) > colCodec.MaxChunks() {
+		return nil, errors.New("number of chunks exceeds the maximum")
+	}
+
+	eds := ExtendedDataSquare{dataSquare: ds, codec: rowCodec, colCodec: colCodec}
+	err = eds.erasureExtendSquare(ctx, rowCodec)
 	if err != nil {
 		return nil, err
 	}
@@ -73,8 +146,13 @@ func ImportExtendedDataSquare(
 	data [][]byte,
 	codec Codec,
 	treeCreatorFn TreeConstructorFn,
+	opts ...Option,
 ) (*ExtendedDataSquare, error) {
-	if len(data) > 4*codec.MaxChunks() {
+	if err := validateExtensionFactor(codec); err != nil {
+		return nil, err
+	}
+	factor := extensionFactor(codec)
+	if len(data) > int(factor*factor)*codec.MaxChunks() {
 		return nil, errors.New("number of chunks exceeds the maximum")
 	}
 
@@ -82,22 +160,94 @@ func ImportExtendedDataSquare(
 	if err != nil {
 		return nil, err
 	}
+	ds.opts = newOptions(opts)
 
-	eds := ExtendedDataSquare{dataSquare: ds, codec: codec}
-	if eds.width%2 != 0 {
-		return nil, errors.New("square width must be even")
+	eds := ExtendedDataSquare{dataSquare: ds, codec: codec, colCodec: codec}
+	if err := eds.setOriginalDataSize(); err != nil {
+		return nil, err
+	}
+	if err := eds.useStorage(eds.width, eds.height); err != nil {
+		return nil, err
 	}
 
-	eds.originalDataWidth = eds.width / 2
+	return &eds, nil
+}
+
+// ImportExtendedDataRectangle imports extended data with height rows,
+// represented as flattened chunks of data, whose rows were extended with
+// rowCodec and whose columns were extended with colCodec.
+func ImportExtendedDataRectangle(
+	data [][]byte,
+	height uint,
+	rowCodec Codec,
+	colCodec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...Option,
+) (*ExtendedDataSquare, error) {
+	if err := validateExtensionFactor(rowCodec); err != nil {
+		return nil, err
+	}
+	if err := validateExtensionFactor(colCodec); err != nil {
+		return nil, err
+	}
+
+	ds, err := newDataRectangle(data, height, treeCreatorFn)
+	if err != nil {
+		return nil, err
+	}
+	ds.opts = newOptions(opts)
+
+	eds := ExtendedDataSquare{dataSquare: ds, codec: rowCodec, colCodec: colCodec}
+	if err := eds.setOriginalDataSize(); err != nil {
+		return nil, err
+	}
+	if int(eds.originalDataWidth*eds.originalDataWidth) > rowCodec.MaxChunks() ||
+		int(eds.originalDataHeight*eds.originalDataHeight) > colCodec.MaxChunks() {
+		return nil, errors.New("number of chunks exceeds the maximum")
+	}
+	if err := eds.useStorage(eds.width, eds.height); err != nil {
+		return nil, err
+	}
 
 	return &eds, nil
 }
 
-func (eds *ExtendedDataSquare) erasureExtendSquare(codec Codec) error {
+// setOriginalDataSize derives the dimensions of the original data from the
+// dimensions of the extended data and the extension factors of the codecs.
+func (eds *ExtendedDataSquare) setOriginalDataSize() error {
+	rowFactor := extensionFactor(eds.codec)
+	if eds.width%rowFactor != 0 {
+		return fmt.Errorf("square width %d must be a multiple of the extension factor %d", eds.width, rowFactor)
+	}
+	colFactor := extensionFactor(eds.colCodec)
+	if eds.height%colFactor != 0 {
+		return fmt.Errorf("square height %d must be a multiple of the extension factor %d", eds.height, colFactor)
+	}
+
+	eds.originalDataWidth = eds.width / rowFactor
+	eds.originalDataHeight = eds.height / colFactor
+	return nil
+}
+
+// validateExtensionFactor returns an error if codec does not extend data by a
+// supported factor.
+func validateExtensionFactor(codec Codec) error {
+	if factor := extensionFactor(codec); factor < DefaultExtensionFactor {
+		return fmt.Errorf("codec %s has extension factor %d, must be at least %d", codec.Name(), factor, DefaultExtensionFactor)
+	}
+	return nil
+}
+
+// erasureExtendSquare extends the original data, using codec for rows and
+// eds.colCodec for columns. It stops encoding and returns ctx.Err() once ctx
+// is done, leaving the extended quadrants partially populated.
+func (eds *ExtendedDataSquare) erasureExtendSquare(ctx context.Context, codec Codec) error {
 	eds.originalDataWidth = eds.width
+	eds.originalDataHeight = eds.height
 
 	// Extend original square with filler chunks. O represents original data. F
-	// represents filler chunks.
+	// represents filler chunks. With an extension factor f, the extended
+	// quadrants are (f-1) times as wide or high as the original data.
 	//
 	//  ------- -------
 	// |       |       |
@@ -108,11 +258,16 @@ func (eds *ExtendedDataSquare) erasureExtendSquare(codec Codec) error {
 	// |   F   |   F   |
 	// |       |       |
 	//  ------- -------
-	if err := eds.extendSquare(eds.width, bytes.Repeat([]byte{0}, int(eds.chunkSize))); err != nil {
+	extendedWidth := (extensionFactor(codec) - 1) * eds.width
+	extendedHeight := (extensionFactor(eds.colCodec) - 1) * eds.height
+	if err := eds.useStorage(eds.width+extendedWidth, eds.height+extendedHeight); err != nil {
+		return err
+	}
+	if err := eds.extendRectangle(extendedHeight, extendedWidth, bytes.Repeat([]byte{0}, int(eds.chunkSize))); err != nil {
 		return err
 	}
 
-	errs, _ := errgroup.WithContext(context.Background())
+	errs, errsCtx := newWorkGroup(ctx, eds.opts)
 
 	// Populate filler chunks in Q1 and Q2. E represents erasure data.
 	//
@@ -125,23 +280,37 @@ func (eds *ExtendedDataSquare) erasureExtendSquare(codec Codec) error {
 	// |   E   |   F   |
 	// |       |       |
 	//  ------- -------
-	for i := uint(0); i < eds.originalDataWidth; i++ {
+	for i := uint(0); i < eds.originalDataHeight && errsCtx.Err() == nil; i++ {
 		i := i
 
 		// Encode Q0 and populate Q1 with erasure data
 		errs.Go(func() error {
+			if err := errsCtx.Err(); err != nil {
+				return err
+			}
 			return eds.erasureExtendRow(codec, i)
 		})
+	}
+	for i := uint(0); i < eds.originalDataWidth && errsCtx.Err() == nil; i++ {
+		i := i
 
 		// Encode Q0 and populate Q2 with erasure data
 		errs.Go(func() error {
-			return eds.erasureExtendCol(codec, i)
+			if err := errsCtx.Err(); err != nil {
+				return err
+			}
+			return eds.erasureExtendCol(eds.colCodec, i)
 		})
 	}
 
 	if err := errs.Wait(); err != nil {
 		return err
 	}
+	// the group's context is canceled once Wait returns, so the parent
+	// context has to be checked explicitly
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	// Populate filler chunks in Q3. Note that the parity data in `Q3` will be
 	// identical if it is vertically extended from `Q1` or horizontally extended
@@ -156,19 +325,34 @@ func (eds *ExtendedDataSquare) erasureExtendSquare(codec Codec) error {
 	// |   E → |   E   |
 	// |       |       |
 	//  ------- -------
-	for i := eds.originalDataWidth; i < eds.width; i++ {
+	errs, errsCtx = newWorkGroup(ctx, eds.opts)
+	for i := eds.originalDataHeight; i < eds.height && errsCtx.Err() == nil; i++ {
 		i := i
 
 		// Encode Q2 and populate Q3 with erasure data
 		errs.Go(func() error {
+			if err := errsCtx.Err(); err != nil {
+				return err
+			}
 			return eds.erasureExtendRow(codec, i)
 		})
 	}
 
-	return errs.Wait()
+	if err := errs.Wait(); err != nil {
+		return err
+	}
+	return ctx.Err()
 }
 
 func (eds *ExtendedDataSquare) erasureExtendRow(codec Codec, i uint) error {
+	if enc, ok := codec.(EncodeIntoCodec); ok && eds.buf != nil {
+		// the parity shares are already kept in the buffer of the square
+		parityShares := eds.rowSlice(i, eds.originalDataWidth, eds.width-eds.originalDataWidth)
+		if err := enc.EncodeInto(eds.rowSlice(i, 0, eds.originalDataWidth), parityShares); err != nil {
+			return err
+		}
+		return eds.setRowSlice(i, eds.originalDataWidth, parityShares)
+	}
 	parityShares, err := codec.Encode(eds.rowSlice(i, 0, eds.originalDataWidth))
 	if err != nil {
 		return err
@@ -177,16 +361,43 @@ func (eds *ExtendedDataSquare) erasureExtendRow(codec Codec, i uint) error {
 }
 
 func (eds *ExtendedDataSquare) erasureExtendCol(codec Codec, i uint) error {
-	parityShares, err := codec.Encode(eds.colSlice(0, i, eds.originalDataWidth))
+	if enc, ok := codec.(EncodeIntoCodec); ok && eds.buf != nil {
+		// the parity shares are already kept in the buffer of the square
+		parityShares := eds.colSlice(eds.originalDataHeight, i, eds.height-eds.originalDataHeight)
+		if err := enc.EncodeInto(eds.colSlice(0, i, eds.originalDataHeight), parityShares); err != nil {
+			return err
+		}
+		return eds.setColSlice(eds.originalDataHeight, i, parityShares)
+	}
+	parityShares, err := codec.Encode(eds.colSlice(0, i, eds.originalDataHeight))
 	if err != nil {
 		return err
 	}
-	return eds.setColSlice(eds.originalDataWidth, i, parityShares)
+	return eds.setColSlice(eds.originalDataHeight, i, parityShares)
+}
+
+func (eds *ExtendedDataSquare) deepCopy() (ExtendedDataSquare, error) {
+	imported, err := ImportExtendedDataRectangle(eds.Flattened(), eds.height, eds.codec, eds.colCodec, eds.createTreeFn)
+	if err != nil {
+		return ExtendedDataSquare{}, err
+	}
+	imported.opts = eds.opts
+	return *imported, nil
+}
+
+// codecFor returns the codec that is used to extend and repair the provided
+// axis.
+func (eds *ExtendedDataSquare) codecFor(axis Axis) Codec {
+	if axis == Col {
+		return eds.colCodec
+	}
+	return eds.codec
 }
 
-func (eds *ExtendedDataSquare) deepCopy(codec Codec) (ExtendedDataSquare, error) {
-	imported, err := ImportExtendedDataSquare(eds.Flattened(), codec, eds.createTreeFn)
-	return *imported, err
+// isRectangle returns true if the number of rows and columns or the codecs
+// used to extend them differ.
+func (eds *ExtendedDataSquare) isRectangle() bool {
+	return eds.width != eds.height || eds.codec.Name() != eds.colCodec.Name()
 }
 
 // Col returns a column slice.
@@ -228,7 +439,19 @@ func deepCopy(original [][]byte) [][]byte {
 	return dest
 }
 
-// Width returns the width of the square.
+// Width returns the width of the square, i.e. its number of columns.
 func (eds *ExtendedDataSquare) Width() uint {
 	return eds.width
 }
+
+// Height returns the height of the square, i.e. its number of rows. It is
+// equal to Width unless the square is a rectangle.
+func (eds *ExtendedDataSquare) Height() uint {
+	return eds.height
+}
+
+// ExtensionFactor returns the ratio of the width of the square to the width
+// of the original data.
+func (eds *ExtendedDataSquare) ExtensionFactor() uint {
+	return extensionFactor(eds.codec)
+}
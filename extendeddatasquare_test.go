@@ -2,6 +2,7 @@ package rsmt2d
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const ShardSize = 64
@@ -71,6 +73,157 @@ func TestComputeExtendedDataSquare(t *testing.T) {
 	}
 }
 
+func TestComputeExtendedDataSquareExtensionFactor(t *testing.T) {
+	for _, codec := range []Codec{
+		NewLeoRSCodec(WithExtensionFactor(4)),
+		NewRSGF8Codec(WithExtensionFactor(4)),
+	} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			eds, err := ComputeExtendedDataSquare([][]byte{
+				ones, twos,
+				threes, fours,
+			}, codec, NewDefaultTree)
+			require.NoError(t, err)
+			assert.Equal(t, uint(8), eds.Width())
+			assert.Equal(t, uint(2), eds.originalDataWidth)
+			assert.Equal(t, uint(4), eds.ExtensionFactor())
+
+			// Every row and column must be a valid codeword, including the
+			// ones in the parity quadrants.
+			for i := uint(0); i < eds.Width(); i++ {
+				for _, vector := range [][][]byte{eds.Row(i), eds.Col(i)} {
+					parity, err := codec.Encode(vector[:eds.originalDataWidth])
+					require.NoError(t, err)
+					assert.Equal(t, vector[eds.originalDataWidth:], parity)
+				}
+			}
+
+			imported, err := ImportExtendedDataSquare(eds.Flattened(), codec, NewDefaultTree)
+			require.NoError(t, err)
+			assert.Equal(t, eds.originalDataWidth, imported.originalDataWidth)
+		})
+	}
+
+	t.Run("invalid extension factor", func(t *testing.T) {
+		_, err := ComputeExtendedDataSquare([][]byte{ones}, NewLeoRSCodec(WithExtensionFactor(1)), NewDefaultTree)
+		assert.Error(t, err)
+	})
+
+	t.Run("zero extension factor", func(t *testing.T) {
+		for _, codec := range []Codec{
+			NewLeoRSCodec(WithExtensionFactor(0)),
+			NewRSGF8Codec(WithExtensionFactor(0)),
+			&LeoRSCodec{},
+			&RSGF8Codec{},
+		} {
+			assert.Equal(t, uint(DefaultExtensionFactor), extensionFactor(codec))
+			assert.Positive(t, codec.MaxChunks())
+			eds, err := ComputeExtendedDataSquare([][]byte{
+				ones, twos,
+				threes, fours,
+			}, codec, NewDefaultTree)
+			require.NoError(t, err, codec.Name())
+			assert.Equal(t, uint(4), eds.Width())
+		}
+	})
+
+	t.Run("invalid extension factor from buffer", func(t *testing.T) {
+		_, err := ComputeExtendedDataSquareFromBuffer(ones, uint(len(ones)), NewLeoRSCodec(WithExtensionFactor(1)), NewDefaultTree)
+		assert.Error(t, err)
+	})
+
+	t.Run("import width not a multiple of the extension factor", func(t *testing.T) {
+		_, err := ImportExtendedDataSquare([][]byte{
+			ones, twos,
+			threes, fours,
+		}, NewLeoRSCodec(WithExtensionFactor(4)), NewDefaultTree)
+		assert.Error(t, err)
+	})
+}
+
+func TestComputeExtendedDataRectangle(t *testing.T) {
+	rowCodec := NewLeoRSCodec()
+	colCodec := NewLeoRSCodec(WithExtensionFactor(4))
+
+	// 2 rows of 3 shares
+	eds, err := ComputeExtendedDataRectangle([][]byte{
+		ones, twos, threes,
+		fours, fives, eights,
+	}, 2, rowCodec, colCodec, NewDefaultTree)
+	require.NoError(t, err)
+	assert.Equal(t, uint(6), eds.Width())
+	assert.Equal(t, uint(8), eds.Height())
+	assert.Equal(t, uint(3), eds.originalDataWidth)
+	assert.Equal(t, uint(2), eds.originalDataHeight)
+
+	for r := uint(0); r < eds.Height(); r++ {
+		row := eds.Row(r)
+		parity, err := rowCodec.Encode(row[:eds.originalDataWidth])
+		require.NoError(t, err)
+		assert.Equal(t, row[eds.originalDataWidth:], parity)
+	}
+	for c := uint(0); c < eds.Width(); c++ {
+		col := eds.Col(c)
+		parity, err := colCodec.Encode(col[:eds.originalDataHeight])
+		require.NoError(t, err)
+		assert.Equal(t, col[eds.originalDataHeight:], parity)
+	}
+
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	assert.Len(t, rowRoots, 8)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+	assert.Len(t, colRoots, 6)
+
+	imported, err := ImportExtendedDataRectangle(eds.Flattened(), eds.Height(), rowCodec, colCodec, NewDefaultTree)
+	require.NoError(t, err)
+	assert.Equal(t, eds.originalDataWidth, imported.originalDataWidth)
+	assert.Equal(t, eds.originalDataHeight, imported.originalDataHeight)
+
+	t.Run("json round trip", func(t *testing.T) {
+		require.NoError(t, RegisterCodec(colCodec.Name(), colCodec))
+		defer UnregisterCodec(colCodec.Name())
+
+		edsBytes, err := json.Marshal(eds)
+		require.NoError(t, err)
+
+		var decoded ExtendedDataSquare
+		require.NoError(t, json.Unmarshal(edsBytes, &decoded))
+		assert.Equal(t, eds.squareRow, decoded.squareRow)
+		assert.Equal(t, colCodec.Name(), decoded.colCodec.Name())
+	})
+}
+
+func TestComputeExtendedDataSquareWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	eds, err := ComputeExtendedDataSquareWithContext(ctx, genRandDS(8), NewLeoRSCodec(), NewDefaultTree)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, eds)
+
+	eds, err = ComputeExtendedDataSquareWithContext(context.Background(), genRandDS(8), NewLeoRSCodec(), NewDefaultTree)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(16), eds.Width())
+}
+
+func TestComputeExtendedDataRectangleWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rowCodec := NewLeoRSCodec()
+	colCodec := NewLeoRSCodec(WithExtensionFactor(4))
+	eds, err := ComputeExtendedDataRectangleWithContext(ctx, genRandDS(4)[:8], 2, rowCodec, colCodec, NewDefaultTree)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, eds)
+
+	eds, err = ComputeExtendedDataRectangleWithContext(context.Background(), genRandDS(4)[:8], 2, rowCodec, colCodec, NewDefaultTree)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(8), eds.Width())
+	assert.Equal(t, uint(8), eds.Height())
+}
+
 func TestMarshalJSON(t *testing.T) {
 	codec := NewLeoRSCodec()
 	result, err := ComputeExtendedDataSquare([][]byte{
@@ -96,6 +249,51 @@ func TestMarshalJSON(t *testing.T) {
 	}
 }
 
+func TestUnmarshalJSONUnknownCodec(t *testing.T) {
+	codec := testCodec{NewLeoRSCodec()}
+	result, err := ComputeExtendedDataSquare([][]byte{
+		ones, twos,
+		threes, fours,
+	}, codec, NewDefaultTree)
+	require.NoError(t, err)
+
+	edsBytes, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var eds ExtendedDataSquare
+	err = json.Unmarshal(edsBytes, &eds)
+	var unknownErr *ErrUnknownCodec
+	assert.ErrorAs(t, err, &unknownErr)
+
+	require.NoError(t, RegisterCodec(codec.Name(), codec))
+	defer UnregisterCodec(codec.Name())
+
+	err = json.Unmarshal(edsBytes, &eds)
+	require.NoError(t, err)
+	assert.Equal(t, result.squareRow, eds.squareRow)
+}
+
+func TestMarshalJSONExtensionFactor(t *testing.T) {
+	codec := NewLeoRSCodec(WithExtensionFactor(4))
+	require.Equal(t, "Leopard-4x", codec.Name())
+	require.NoError(t, RegisterCodec(codec.Name(), codec))
+	defer UnregisterCodec(codec.Name())
+
+	result, err := ComputeExtendedDataSquare([][]byte{
+		ones, twos,
+		threes, fours,
+	}, codec, NewDefaultTree)
+	require.NoError(t, err)
+
+	edsBytes, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var eds ExtendedDataSquare
+	require.NoError(t, json.Unmarshal(edsBytes, &eds))
+	assert.Equal(t, result.squareRow, eds.squareRow)
+	assert.Equal(t, result.originalDataWidth, eds.originalDataWidth)
+}
+
 func TestImmutableRoots(t *testing.T) {
 	codec := NewLeoRSCodec()
 	result, err := ComputeExtendedDataSquare([][]byte{
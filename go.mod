@@ -7,10 +7,7 @@ require (
 	github.com/stretchr/testify v1.7.0
 )
 
-require (
-	github.com/klauspost/reedsolomon v1.11.1
-	golang.org/x/sync v0.0.0-20220929204114-8fcdb60fdcc0
-)
+require github.com/klauspost/reedsolomon v1.11.1
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
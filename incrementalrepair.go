@@ -0,0 +1,252 @@
+package rsmt2d
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IncrementalRepairer repairs an ExtendedDataSquare while its shares arrive.
+// Every row or column is decoded as soon as it has enough shares, and the
+// shares it recovers are used to decode further rows and columns, like Repair
+// does. Shares are verified against the roots the same way as in Repair.
+//
+// The WithRepairObserver option is notified about every repaired row and
+// column, and Stats summarizes the work done so far, like RepairWithStats.
+//
+// It is safe to call the methods of an IncrementalRepairer concurrently. The
+// ExtendedDataSquare must not be modified by other means while it is being
+// repaired.
+type IncrementalRepairer struct {
+	eds      *ExtendedDataSquare
+	rowRoots [][]byte
+	colRoots [][]byte
+
+	mu    sync.Mutex
+	stats *repairStats
+	// rowKnown and colKnown are the number of non-nil shares in every row and
+	// column.
+	rowKnown []uint
+	colKnown []uint
+	// missing is the number of nil shares in the square.
+	missing uint
+	err     error
+	done    chan struct{}
+}
+
+// axisIndex identifies a row or column.
+type axisIndex struct {
+	axis  Axis
+	index uint
+}
+
+// NewIncrementalRepairer returns an IncrementalRepairer for eds, whose missing
+// shares must be nil. The rows and columns that can be repaired with the
+// shares eds already has are repaired immediately. The provided options take
+// precedence over the options the EDS was created with, like in Repair.
+func NewIncrementalRepairer(eds *ExtendedDataSquare, rowRoots [][]byte, colRoots [][]byte, opts ...Option) (*IncrementalRepairer, error) {
+	if uint(len(rowRoots)) != eds.height || uint(len(colRoots)) != eds.width {
+		return nil, fmt.Errorf("expected %d row roots and %d col roots, got %d and %d", eds.height, eds.width, len(rowRoots), len(colRoots))
+	}
+
+	o := eds.opts.with(opts)
+	r := &IncrementalRepairer{
+		eds:      eds,
+		rowRoots: rowRoots,
+		colRoots: colRoots,
+		rowKnown: make([]uint, eds.height),
+		colKnown: make([]uint, eds.width),
+		done:     make(chan struct{}),
+		stats:    &repairStats{observer: o.repairObserver},
+	}
+	for row := uint(0); row < eds.height; row++ {
+		for col := uint(0); col < eds.width; col++ {
+			if eds.squareRow[row][col] != nil {
+				r.rowKnown[row]++
+				r.colKnown[col]++
+			} else {
+				r.missing++
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start := time.Now()
+	err := eds.prerepairSanityCheck(context.Background(), o, rowRoots, colRoots, nil, r.stats)
+	r.stats.sanityCheckDuration = time.Since(start)
+	if err != nil {
+		r.finish(err)
+		return r, nil
+	}
+
+	var queue []axisIndex
+	for row := uint(0); row < eds.height; row++ {
+		if r.canSolve(Row, row) {
+			queue = append(queue, axisIndex{Row, row})
+		}
+	}
+	for col := uint(0); col < eds.width; col++ {
+		if r.canSolve(Col, col) {
+			queue = append(queue, axisIndex{Col, col})
+		}
+	}
+	r.solve(queue)
+
+	return r, nil
+}
+
+// AddShare adds the share of the cell at the provided row and column and
+// repairs the rows and columns that can be repaired as a result. Shares of
+// cells that are already known, e.g. because they were repaired, are ignored.
+// The returned error is only about the share itself; whether the repair failed
+// is reported by Err.
+func (r *IncrementalRepairer) AddShare(row uint, col uint, share []byte) error {
+	if row >= r.eds.height || col >= r.eds.width {
+		return fmt.Errorf("cell (%d, %d) is outside of the %dx%d square", row, col, r.eds.height, r.eds.width)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil || r.eds.squareRow[row][col] != nil {
+		return nil
+	}
+	if len(share) == 0 {
+		return errors.New("cannot add an empty share")
+	}
+	// a square without any shares and without a known share size takes the
+	// size of the first share; otherwise SetCell checks the size
+	if r.missing == r.eds.width*r.eds.height && r.eds.chunkSize == 0 {
+		r.eds.chunkSize = uint(len(share))
+	}
+	if err := r.eds.SetCell(row, col, share); err != nil {
+		return err
+	}
+	r.setKnown(row, col)
+
+	var queue []axisIndex
+	if r.canSolve(Row, row) {
+		queue = append(queue, axisIndex{Row, row})
+	}
+	if r.canSolve(Col, col) {
+		queue = append(queue, axisIndex{Col, col})
+	}
+	r.solve(queue)
+	return nil
+}
+
+// Done returns a channel that is closed once the square is complete or
+// repairing it failed.
+func (r *IncrementalRepairer) Done() <-chan struct{} {
+	return r.done
+}
+
+// Err returns nil while the square is being repaired or once it is complete.
+// If repairing failed, e.g. because the square contains Byzantine data, the
+// error is returned. In that case, the square is left as described in Repair.
+func (r *IncrementalRepairer) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Stats returns a summary of the work done to repair the square so far. Every
+// batch of rows and columns repaired on creation or by AddShare counts as an
+// iteration.
+func (r *IncrementalRepairer) Stats() RepairStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats.summary()
+}
+
+// canSolve returns true if the row or column is incomplete but has enough
+// shares to be decoded.
+func (r *IncrementalRepairer) canSolve(axis Axis, index uint) bool {
+	if axis == Row {
+		return r.rowKnown[index] >= r.eds.originalDataWidth && r.rowKnown[index] < r.eds.width
+	}
+	return r.colKnown[index] >= r.eds.originalDataHeight && r.colKnown[index] < r.eds.height
+}
+
+func (r *IncrementalRepairer) setKnown(row uint, col uint) {
+	r.rowKnown[row]++
+	r.colKnown[col]++
+	r.missing--
+}
+
+// solve repairs the queued rows and columns, queueing the orthogonal rows and
+// columns that can be repaired with the recovered shares.
+func (r *IncrementalRepairer) solve(queue []axisIndex) {
+	if len(queue) > 0 && r.err == nil {
+		r.stats.startIteration()
+		start := time.Now()
+		defer func() { r.stats.solveDuration += time.Since(start) }()
+	}
+	for len(queue) > 0 && r.err == nil {
+		next := queue[0]
+		queue = queue[1:]
+		if !r.canSolve(next.axis, next.index) {
+			continue
+		}
+
+		// the orthogonal rows or columns of the shares that will be recovered
+		var missing []uint
+		var progressMade bool
+		var err error
+		if next.axis == Row {
+			for col, share := range r.eds.row(next.index) {
+				if share == nil {
+					missing = append(missing, uint(col))
+				}
+			}
+			_, progressMade, err = r.eds.solveCrosswordRow(int(next.index), r.rowRoots, r.colRoots, r.stats)
+		} else {
+			for row, share := range r.eds.col(next.index) {
+				if share == nil {
+					missing = append(missing, uint(row))
+				}
+			}
+			_, progressMade, err = r.eds.solveCrosswordCol(int(next.index), r.rowRoots, r.colRoots, r.stats)
+		}
+		if err != nil {
+			r.finish(err)
+			return
+		}
+		if !progressMade {
+			continue
+		}
+
+		for _, i := range missing {
+			if next.axis == Row {
+				r.setKnown(next.index, i)
+				if r.canSolve(Col, i) {
+					queue = append(queue, axisIndex{Col, i})
+				}
+			} else {
+				r.setKnown(i, next.index)
+				if r.canSolve(Row, i) {
+					queue = append(queue, axisIndex{Row, i})
+				}
+			}
+		}
+	}
+
+	if r.err == nil && r.missing == 0 {
+		r.finish(nil)
+	}
+}
+
+// finish records the outcome of the repair and closes the done channel.
+func (r *IncrementalRepairer) finish(err error) {
+	select {
+	case <-r.done:
+		return
+	default:
+	}
+	r.err = r.eds.attachBadEncodingProofs(err)
+	close(r.done)
+}
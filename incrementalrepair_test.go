@@ -0,0 +1,172 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncrementalRepairer(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+	width := original.Width()
+
+	empty := func(t *testing.T) *ExtendedDataSquare {
+		eds, err := ImportExtendedDataSquare(make([][]byte, width*width), codec, NewDefaultTree)
+		require.NoError(t, err)
+		return eds
+	}
+
+	t.Run("repairs as shares arrive", func(t *testing.T) {
+		eds := empty(t)
+		r, err := NewIncrementalRepairer(eds, rowRoots, colRoots)
+		require.NoError(t, err)
+
+		// Adding the shares of the top left quadrant completes the square
+		// once the last of them arrives.
+		for row := uint(0); row < original.originalDataWidth; row++ {
+			for col := uint(0); col < original.originalDataWidth; col++ {
+				select {
+				case <-r.Done():
+					t.Fatal("repair finished before all required shares were added")
+				default:
+				}
+				require.NoError(t, r.AddShare(row, col, original.GetCell(row, col)))
+			}
+		}
+
+		<-r.Done()
+		assert.NoError(t, r.Err())
+		assert.Equal(t, original.Flattened(), eds.Flattened())
+
+		// shares of cells that are already known are ignored
+		assert.NoError(t, r.AddShare(0, 0, original.GetCell(0, 0)))
+	})
+
+	t.Run("random order", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		for n := 0; n < 20; n++ {
+			eds := empty(t)
+			r, err := NewIncrementalRepairer(eds, rowRoots, colRoots)
+			require.NoError(t, err)
+
+			// received contains the shares added so far
+			received := make([][]byte, width*width)
+			for _, i := range rng.Perm(int(width * width)) {
+				row, col := uint(i)/width, uint(i)%width
+				require.NoError(t, r.AddShare(row, col, original.GetCell(row, col)))
+				received[i] = original.GetCell(row, col)
+
+				// the repair finishes as soon as the received shares suffice
+				imported, err := ImportExtendedDataSquare(received, codec, NewDefaultTree)
+				require.NoError(t, err)
+				select {
+				case <-r.Done():
+					require.True(t, imported.CanRepair())
+				default:
+					require.False(t, imported.CanRepair())
+					continue
+				}
+				break
+			}
+			require.NoError(t, r.Err())
+			assert.Equal(t, original.Flattened(), eds.Flattened())
+		}
+	})
+
+	t.Run("already repairable", func(t *testing.T) {
+		flattened := original.Flattened()
+		flattened[0], flattened[9] = nil, nil
+		eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+		require.NoError(t, err)
+
+		r, err := NewIncrementalRepairer(eds, rowRoots, colRoots)
+		require.NoError(t, err)
+		<-r.Done()
+		assert.NoError(t, r.Err())
+		assert.Equal(t, original.Flattened(), eds.Flattened())
+	})
+
+	t.Run("byzantine", func(t *testing.T) {
+		eds := empty(t)
+		r, err := NewIncrementalRepairer(eds, rowRoots, colRoots)
+		require.NoError(t, err)
+
+		corruptChunk := bytes.Repeat([]byte{66}, len(original.GetCell(0, 0)))
+		require.NoError(t, r.AddShare(0, 0, corruptChunk))
+		for col := uint(1); col < original.originalDataWidth; col++ {
+			require.NoError(t, r.AddShare(0, col, original.GetCell(0, col)))
+		}
+
+		<-r.Done()
+		var byzData *ErrByzantineData
+		require.ErrorAs(t, r.Err(), &byzData)
+		assert.Equal(t, Row, byzData.Axis)
+		assert.Equal(t, uint(0), byzData.Index)
+	})
+
+	t.Run("invalid share", func(t *testing.T) {
+		r, err := NewIncrementalRepairer(empty(t), rowRoots, colRoots)
+		require.NoError(t, err)
+		assert.Error(t, r.AddShare(width, 0, original.GetCell(0, 0)))
+		require.NoError(t, r.AddShare(0, 0, original.GetCell(0, 0)))
+		assert.Error(t, r.AddShare(0, 1, []byte{1}))
+	})
+
+	t.Run("share size", func(t *testing.T) {
+		r, err := NewIncrementalRepairer(empty(t), rowRoots, colRoots)
+		require.NoError(t, err)
+		assert.Error(t, r.AddShare(0, 0, []byte{}))
+
+		// the first share must match a share size that is already known
+		eds := empty(t)
+		eds.chunkSize = uint(len(original.GetCell(0, 0)))
+		r, err = NewIncrementalRepairer(eds, rowRoots, colRoots)
+		require.NoError(t, err)
+		assert.Error(t, r.AddShare(0, 0, []byte{1}))
+		assert.Equal(t, uint(len(original.GetCell(0, 0))), eds.chunkSize)
+		assert.NoError(t, r.AddShare(0, 0, original.GetCell(0, 0)))
+	})
+
+	t.Run("stats and observer", func(t *testing.T) {
+		var events []RepairEvent
+		observer := RepairObserverFunc(func(event RepairEvent) {
+			events = append(events, event)
+		})
+		eds := empty(t)
+		r, err := NewIncrementalRepairer(eds, rowRoots, colRoots, WithRepairObserver(observer))
+		require.NoError(t, err)
+		for row := uint(0); row < original.originalDataWidth; row++ {
+			for col := uint(0); col < original.originalDataWidth; col++ {
+				require.NoError(t, r.AddShare(row, col, original.GetCell(row, col)))
+			}
+		}
+		<-r.Done()
+		require.NoError(t, r.Err())
+
+		stats := r.Stats()
+		added := int(original.originalDataWidth * original.originalDataWidth)
+		assert.Equal(t, int(width*width)-added, stats.SharesRecovered)
+		assert.Equal(t, len(events), stats.DecodesSucceeded)
+		assert.Positive(t, stats.RootComputations)
+		assert.Positive(t, stats.Iterations)
+		filled := 0
+		for _, event := range events {
+			filled += event.SharesFilled
+		}
+		assert.Equal(t, stats.SharesRecovered, filled)
+	})
+
+	t.Run("invalid roots", func(t *testing.T) {
+		_, err := NewIncrementalRepairer(empty(t), rowRoots[1:], colRoots)
+		assert.Error(t, err)
+	})
+}
@@ -1,51 +1,76 @@
 package rsmt2d
 
 import (
-	"sync"
+	"fmt"
 
 	"github.com/klauspost/reedsolomon"
 )
 
-var _ Codec = &LeoRSCodec{}
+var (
+	_ ExtensionFactorCodec = &LeoRSCodec{}
+	_ EncodeIntoCodec      = &LeoRSCodec{}
+	_ DecodeIntoCodec      = &LeoRSCodec{}
+)
 
 func init() {
 	registerCodec(Leopard, NewLeoRSCodec())
 }
 
+// leopardMaxShards is the maximum number of data + parity shards supported by
+// the Leopard codec.
+const leopardMaxShards = 65536
+
 type LeoRSCodec struct {
+	cfg codecConfig
+
 	// Cache the encoders of various sizes to not have to re-instantiate those
-	// as it is costly.
-	//
-	// Note that past sizes are not removed from the cache at all as the various
-	// data sizes are expected to relatively small and will not cause any memory issue.
-	//
-	// TODO: switch to a generic version of sync.Map with type reedsolomon.Encoder
-	// once it made it into the standard lib
-	encCache sync.Map
+	// as it is costly. The least recently used encoders are evicted once the
+	// cache holds cfg.encoderCacheSize encoders.
+	encCache encoderCache
 }
 
 func (l *LeoRSCodec) Encode(data [][]byte) ([][]byte, error) {
-	dataLen := len(data)
-	enc, err := l.loadOrInitEncoder(dataLen)
+	enc, parityLen, err := l.encoderFor(len(data))
 	if err != nil {
 		return nil, err
 	}
+	parity := newParityShares(parityLen, len(data[0]))
+	if err := encodeShards(enc, data, parity); err != nil {
+		return nil, err
+	}
+	return parity, nil
+}
 
-	shards := make([][]byte, dataLen*2)
-	copy(shards, data)
-	for i := dataLen; i < len(shards); i++ {
-		shards[i] = make([]byte, len(data[0]))
+// EncodeInto encodes data like Encode, but writes the parity shares into
+// parity.
+func (l *LeoRSCodec) EncodeInto(data [][]byte, parity [][]byte) error {
+	enc, parityLen, err := l.encoderFor(len(data))
+	if err != nil {
+		return err
 	}
+	if len(parity) != parityLen {
+		return fmt.Errorf("expected %d parity shares, got %d", parityLen, len(parity))
+	}
+	return encodeShards(enc, data, parity)
+}
 
-	if err := enc.Encode(shards); err != nil {
-		return nil, err
+// encoderFor returns the encoder for dataLen original shares and the number
+// of parity shares it computes.
+func (l *LeoRSCodec) encoderFor(dataLen int) (reedsolomon.Encoder, int, error) {
+	dataLen, parityLen, err := l.cfg.shardCounts(dataLen * int(l.cfg.factor()))
+	if err != nil {
+		return nil, 0, err
 	}
-	return shards[dataLen:], nil
+	enc, err := l.loadOrInitEncoder(dataLen, parityLen)
+	return enc, parityLen, err
 }
 
 func (l *LeoRSCodec) Decode(data [][]byte) ([][]byte, error) {
-	half := len(data) / 2
-	enc, err := l.loadOrInitEncoder(half)
+	dataLen, parityLen, err := l.cfg.shardCounts(len(data))
+	if err != nil {
+		return nil, err
+	}
+	enc, err := l.loadOrInitEncoder(dataLen, parityLen)
 	if err != nil {
 		return nil, err
 	}
@@ -53,27 +78,59 @@ func (l *LeoRSCodec) Decode(data [][]byte) ([][]byte, error) {
 	return data, err
 }
 
-func (l *LeoRSCodec) loadOrInitEncoder(dataLen int) (reedsolomon.Encoder, error) {
-	enc, ok := l.encCache.Load(dataLen)
-	if !ok {
-		var err error
-		enc, err = reedsolomon.New(dataLen, dataLen, reedsolomon.WithLeopardGF(true))
-		if err != nil {
-			return nil, err
+// DecodeInto decodes data like Decode, but reconstructs every missing share
+// data[i] into buffers[i] and sets data[i] to it.
+func (l *LeoRSCodec) DecodeInto(data [][]byte, buffers [][]byte) error {
+	dataLen, parityLen, err := l.cfg.shardCounts(len(data))
+	if err != nil {
+		return err
+	}
+	enc, err := l.loadOrInitEncoder(dataLen, parityLen)
+	if err != nil {
+		return err
+	}
+	return reconstructInto(enc, data, buffers)
+}
+
+func (l *LeoRSCodec) loadOrInitEncoder(dataLen int, parityLen int) (reedsolomon.Encoder, error) {
+	return l.encCache.get(dataLen, func() (reedsolomon.Encoder, error) {
+		return reedsolomon.New(dataLen, parityLen, reedsolomon.WithLeopardGF(true))
+	})
+}
+
+// PrewarmEncoders creates and caches the encoders for original data of the
+// provided widths, e.g. the widths of the squares a node expects, so that
+// extending and repairing these squares does not pay for creating encoders.
+// Prewarming more widths than the cache holds evicts the first ones.
+func (l *LeoRSCodec) PrewarmEncoders(widths ...uint) error {
+	for _, width := range widths {
+		if _, _, err := l.encoderFor(int(width)); err != nil {
+			return err
 		}
-		l.encCache.Store(dataLen, enc)
 	}
-	return enc.(reedsolomon.Encoder), nil
+	return nil
+}
+
+// EncoderCacheStats returns the usage of the encoder cache of the codec.
+func (l *LeoRSCodec) EncoderCacheStats() EncoderCacheStats {
+	return l.encCache.stats()
 }
 
 func (l *LeoRSCodec) MaxChunks() int {
-	return 32768 * 32768
+	maxDataLen := leopardMaxShards / int(l.cfg.factor())
+	return maxDataLen * maxDataLen
 }
 
 func (l *LeoRSCodec) Name() string {
-	return Leopard
+	return l.cfg.name(Leopard)
+}
+
+// ExtensionFactor returns the ratio of extended to original shares.
+func (l *LeoRSCodec) ExtensionFactor() uint {
+	return l.cfg.factor()
 }
 
-func NewLeoRSCodec() *LeoRSCodec {
-	return &LeoRSCodec{}
+func NewLeoRSCodec(opts ...CodecOption) *LeoRSCodec {
+	cfg := newCodecConfig(opts)
+	return &LeoRSCodec{cfg: cfg, encCache: encoderCache{capacity: cfg.encoderCacheSize}}
 }
@@ -0,0 +1,98 @@
+//go:build linux || darwin || freebsd || openbsd || dragonfly
+
+package rsmt2d
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var _ Storage = &MappedFile{}
+
+// MappedFile is a Storage backed by a memory-mapped file. The shares of a
+// square kept in a MappedFile are paged in from disk when they are accessed
+// instead of being held in memory, and they are written back to the file. The
+// row and column indexes of the square are still held in memory, see
+// WithStorage.
+type MappedFile struct {
+	file *os.File
+	data []byte
+}
+
+// CreateMappedFile creates the file at path, or truncates it if it exists,
+// with a size of size bytes and maps it into memory. The size of a square of
+// width by height shares of shareSize bytes is width*height*shareSize.
+func CreateMappedFile(path string, size int) (*MappedFile, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid size %d", size)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Truncate(int64(size)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return mapFile(file, size)
+}
+
+// OpenMappedFile maps the existing file at path into memory, e.g. to import a
+// square that was kept in a MappedFile created by CreateMappedFile.
+func OpenMappedFile(path string) (*MappedFile, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return mapFile(file, int(info.Size()))
+}
+
+func mapFile(file *os.File, size int) (*MappedFile, error) {
+	m := &MappedFile{file: file}
+	// empty files cannot be mapped
+	if size > 0 {
+		data, err := syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		m.data = data
+	}
+	return m, nil
+}
+
+// Bytes returns the mapped memory of the file.
+func (m *MappedFile) Bytes() []byte {
+	return m.data
+}
+
+// Sync writes the mapped memory back to the file and waits until it is
+// stored.
+func (m *MappedFile) Sync() error {
+	if len(m.data) > 0 {
+		_, _, errno := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&m.data[0])), uintptr(len(m.data)), syscall.MS_SYNC)
+		if errno != 0 {
+			return errno
+		}
+	}
+	return m.file.Sync()
+}
+
+// Close unmaps and closes the file. Squares kept in the file must not be used
+// afterwards.
+func (m *MappedFile) Close() error {
+	var err error
+	if m.data != nil {
+		err = syscall.Munmap(m.data)
+		m.data = nil
+	}
+	return errors.Join(err, m.file.Close())
+}
@@ -0,0 +1,56 @@
+//go:build linux || darwin || freebsd || openbsd || dragonfly
+
+package rsmt2d
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMappedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eds")
+	want := createTestEds(NewLeoRSCodec(), ShardSize)
+	rowRoots, err := want.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := want.ColRoots()
+	require.NoError(t, err)
+
+	file, err := CreateMappedFile(path, 16*ShardSize)
+	require.NoError(t, err)
+	ods := append(want.Row(0)[:2], want.Row(1)[:2]...)
+	eds, err := ComputeExtendedDataSquare(ods, want.codec, NewDefaultTree, WithStorage(file))
+	require.NoError(t, err)
+	assertStored(t, eds, file.Bytes())
+	require.NoError(t, file.Sync())
+	require.NoError(t, file.Close())
+
+	file, err = OpenMappedFile(path)
+	require.NoError(t, err)
+	defer file.Close()
+	assert.Equal(t, flattenChunks(want.Flattened()), file.Bytes())
+
+	// import the square from the file without copying, dropping some shares
+	shares := make([][]byte, 16)
+	for i := range shares {
+		if i%3 != 0 {
+			shares[i] = file.Bytes()[i*ShardSize : (i+1)*ShardSize]
+		}
+	}
+	eds, err = ImportExtendedDataSquare(shares, want.codec, NewDefaultTree, WithStorage(file))
+	require.NoError(t, err)
+	assertStored(t, eds, file.Bytes())
+
+	require.NoError(t, eds.Repair(rowRoots, colRoots))
+	assert.Equal(t, want.squareRow, eds.squareRow)
+}
+
+func TestCreateMappedFileEmpty(t *testing.T) {
+	file, err := CreateMappedFile(filepath.Join(t.TempDir(), "eds"), 0)
+	require.NoError(t, err)
+	assert.Empty(t, file.Bytes())
+	assert.NoError(t, file.Sync())
+	assert.NoError(t, file.Close())
+}
@@ -0,0 +1,66 @@
+package rsmt2d
+
+import "math/bits"
+
+// Prefixes of the preimages of leaf and inner node hashes, which separate the
+// two domains as in RFC 6962.
+const (
+	leafPrefix byte = 0
+	nodePrefix byte = 1
+)
+
+// nodeHasher combines the hashes of two sibling subtrees into the hash of
+// their parent.
+type nodeHasher func(left []byte, right []byte) []byte
+
+// splitPoint returns the number of leaves in the left subtree of a tree with
+// n > 1 leaves, i.e. the largest power of two smaller than n, as in RFC 6962.
+func splitPoint(n uint) uint {
+	return 1 << (bits.Len(n-1) - 1)
+}
+
+// merkleRoot returns the root of the tree with the non-empty list of
+// leafHashes.
+func merkleRoot(leafHashes [][]byte, hashNode nodeHasher) []byte {
+	if len(leafHashes) == 1 {
+		return leafHashes[0]
+	}
+	k := splitPoint(uint(len(leafHashes)))
+	return hashNode(merkleRoot(leafHashes[:k], hashNode), merkleRoot(leafHashes[k:], hashNode))
+}
+
+// merkleProof returns the hashes of the siblings of the nodes on the path
+// from the leaf at index to the root, starting with the sibling of the leaf.
+func merkleProof(leafHashes [][]byte, index uint, hashNode nodeHasher) [][]byte {
+	if len(leafHashes) <= 1 {
+		return nil
+	}
+	k := splitPoint(uint(len(leafHashes)))
+	if index < k {
+		return append(merkleProof(leafHashes[:k], index, hashNode), merkleRoot(leafHashes[k:], hashNode))
+	}
+	return append(merkleProof(leafHashes[k:], index-k, hashNode), merkleRoot(leafHashes[:k], hashNode))
+}
+
+// merkleRootFromProof returns the root of a tree with numLeaves leaves whose
+// leaf at index has leafHash, according to proof as returned by merkleProof.
+// It returns false if the proof has the wrong length.
+func merkleRootFromProof(leafHash []byte, index uint, numLeaves uint, proof [][]byte, hashNode nodeHasher) ([]byte, bool) {
+	if index >= numLeaves {
+		return nil, false
+	}
+	if numLeaves == 1 {
+		return leafHash, len(proof) == 0
+	}
+	if len(proof) == 0 {
+		return nil, false
+	}
+	k := splitPoint(numLeaves)
+	sibling, proof := proof[len(proof)-1], proof[:len(proof)-1]
+	if index < k {
+		left, ok := merkleRootFromProof(leafHash, index, k, proof, hashNode)
+		return hashNode(left, sibling), ok
+	}
+	right, ok := merkleRootFromProof(leafHash, index-k, numLeaves-k, proof, hashNode)
+	return hashNode(sibling, right), ok
+}
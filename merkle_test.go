@@ -0,0 +1,79 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/minio/sha256-simd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPoint(t *testing.T) {
+	tests := []struct {
+		n    uint
+		want uint
+	}{
+		{2, 1},
+		{3, 2},
+		{4, 2},
+		{5, 4},
+		{8, 4},
+		{9, 8},
+		{128, 64},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, splitPoint(tt.n), "n = %d", tt.n)
+	}
+}
+
+func TestMerkleRootMatchesDefaultTree(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		tree := NewDefaultTree(Row, 0)
+		leafHashes := make([][]byte, n)
+		for i := range leafHashes {
+			leaf := []byte{byte(i)}
+			require.NoError(t, tree.Push(leaf))
+			leafHashes[i] = testHashLeaf(leaf)
+		}
+		want, err := tree.Root()
+		require.NoError(t, err)
+		assert.Equal(t, want, merkleRoot(leafHashes, testHashNode), "n = %d", n)
+	}
+}
+
+func TestMerkleProof(t *testing.T) {
+	for n := uint(1); n <= 9; n++ {
+		leafHashes := make([][]byte, n)
+		for i := range leafHashes {
+			leafHashes[i] = testHashLeaf([]byte{byte(i)})
+		}
+		root := merkleRoot(leafHashes, testHashNode)
+
+		for i := uint(0); i < n; i++ {
+			proof := merkleProof(leafHashes, i, testHashNode)
+			computed, ok := merkleRootFromProof(leafHashes[i], i, n, proof, testHashNode)
+			require.True(t, ok)
+			assert.Equal(t, root, computed, "n = %d, index = %d", n, i)
+
+			_, ok = merkleRootFromProof(leafHashes[i], i, n, append(proof, root), testHashNode)
+			assert.False(t, ok)
+			_, ok = merkleRootFromProof(leafHashes[i], n, n, proof, testHashNode)
+			assert.False(t, ok)
+		}
+	}
+}
+
+func testHashLeaf(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+func testHashNode(left []byte, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
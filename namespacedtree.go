@@ -0,0 +1,202 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/minio/sha256-simd"
+)
+
+var (
+	_ ProvableTree = &NamespacedTree{}
+	_ NamedTree    = &NamespacedTree{}
+	_ HasherTree   = &NamespacedTree{}
+)
+
+// ErrNamespaceOutOfOrder is returned when the shares of the original data are
+// not pushed to a NamespacedTree in ascending order of their namespaces.
+var ErrNamespaceOutOfOrder = errors.New("namespace out of order")
+
+// NamespacedTree is a namespaced Merkle tree over the shares of a row or
+// column. Every node is prefixed with the minimum and maximum namespace of the
+// leaves below it, so that a root shows which namespaces a row or column
+// contains. The namespace of a share of the original data is its first
+// namespaceSize bytes, and the shares of the original data must be pushed in
+// ascending order of their namespaces. Parity shares are assigned the maximum
+// namespace, which is ignored in the maximum namespace of a node unless all
+// leaves below the node are parity shares.
+//
+// Leaves are hashed as minNs || maxNs || SHA256(0x00 || ns || share) and
+// inner nodes as minNs || maxNs || SHA256(0x01 || left || right), with the
+// shape of RFC 6962 trees.
+type NamespacedTree struct {
+	namespaceSize      int
+	axis               Axis
+	originalDataWidth  uint
+	originalDataHeight uint
+
+	// next identifies the cell of the next pushed share.
+	next          SquareIndex
+	lastNamespace []byte
+	leafHashes    [][]byte
+	root          []byte
+}
+
+// NewNamespacedTreeConstructor returns a TreeConstructorFn that creates
+// NamespacedTrees with namespaces of namespaceSize bytes for a square whose
+// original data is originalDataWidth shares wide and originalDataHeight shares
+// high. It panics if namespaceSize is not positive.
+func NewNamespacedTreeConstructor(namespaceSize int, originalDataWidth uint, originalDataHeight uint) TreeConstructorFn {
+	if namespaceSize <= 0 {
+		panic(fmt.Sprintf("namespace size must be positive, got %d", namespaceSize))
+	}
+	return func(axis Axis, index uint) Tree {
+		return &NamespacedTree{
+			namespaceSize:      namespaceSize,
+			axis:               axis,
+			originalDataWidth:  originalDataWidth,
+			originalDataHeight: originalDataHeight,
+			next:               SquareIndex{Axis: index},
+		}
+	}
+}
+
+// Push adds the share at the next cell of the row or column to the tree.
+func (t *NamespacedTree) Push(data []byte) error {
+	if len(data) < t.namespaceSize {
+		return fmt.Errorf("share of %d bytes is shorter than the namespace size %d", len(data), t.namespaceSize)
+	}
+	ns := t.namespace(t.next, data)
+	if t.lastNamespace != nil && bytes.Compare(ns, t.lastNamespace) < 0 {
+		return fmt.Errorf("%w: share %d of %s %d has namespace %x after %x",
+			ErrNamespaceOutOfOrder, t.next.Cell, t.axis, t.next.Axis, ns, t.lastNamespace)
+	}
+	t.lastNamespace = ns
+	t.leafHashes = append(t.leafHashes, t.hashLeaf(ns, data))
+	t.next.Cell++
+	t.root = nil
+	return nil
+}
+
+// Root returns the root of the tree.
+func (t *NamespacedTree) Root() ([]byte, error) {
+	if t.root == nil {
+		if len(t.leafHashes) == 0 {
+			t.root = t.emptyRoot()
+		} else {
+			t.root = merkleRoot(t.leafHashes, t.hashNode)
+		}
+	}
+	return t.root, nil
+}
+
+// Prove returns the Merkle proof for the leaf at index.
+func (t *NamespacedTree) Prove(index uint) ([][]byte, error) {
+	if index >= uint(len(t.leafHashes)) {
+		return nil, fmt.Errorf("cannot prove leaf %d of tree with %d leaves", index, len(t.leafHashes))
+	}
+	return merkleProof(t.leafHashes, index, t.hashNode), nil
+}
+
+// VerifyProof verifies a Merkle proof created by Prove. The leaf is the share
+// at index of the row or column of the tree, so that parity shares are
+// recognized.
+func (t *NamespacedTree) VerifyProof(root []byte, leaf []byte, proof [][]byte, index uint, numLeaves uint) bool {
+	if len(leaf) < t.namespaceSize {
+		return false
+	}
+	for _, node := range proof {
+		if len(node) != t.nodeSize() {
+			return false
+		}
+	}
+	ns := t.namespace(SquareIndex{Axis: t.next.Axis, Cell: index}, leaf)
+	computed, ok := merkleRootFromProof(t.hashLeaf(ns, leaf), index, numLeaves, proof, t.hashNode)
+	return ok && bytes.Equal(computed, root)
+}
+
+// Name returns the name of the tree, which includes the namespace size.
+func (t *NamespacedTree) Name() string {
+	return fmt.Sprintf("NamespacedTree/sha256/%d", t.namespaceSize)
+}
+
+// NewHash returns a new instance of SHA-256, the hash function of the tree.
+func (t *NamespacedTree) NewHash() hash.Hash {
+	return sha256.New()
+}
+
+// NamespaceRange returns the minimum and maximum namespace of the shares
+// below a root of a NamespacedTree with namespaces of namespaceSize bytes.
+func NamespaceRange(root []byte, namespaceSize int) ([]byte, []byte, error) {
+	if namespaceSize <= 0 || len(root) != 2*namespaceSize+sha256.Size {
+		return nil, nil, fmt.Errorf("invalid root of %d bytes for namespace size %d", len(root), namespaceSize)
+	}
+	return root[:namespaceSize], root[namespaceSize : 2*namespaceSize], nil
+}
+
+// isParity returns true if the share at idx is a parity share.
+func (t *NamespacedTree) isParity(idx SquareIndex) bool {
+	if t.axis == Row {
+		return idx.Axis >= t.originalDataHeight || idx.Cell >= t.originalDataWidth
+	}
+	return idx.Axis >= t.originalDataWidth || idx.Cell >= t.originalDataHeight
+}
+
+// namespace returns the namespace of the share at idx.
+func (t *NamespacedTree) namespace(idx SquareIndex, share []byte) []byte {
+	if t.isParity(idx) {
+		return t.parityNamespace()
+	}
+	return share[:t.namespaceSize]
+}
+
+// parityNamespace returns the maximum namespace, which parity shares have.
+func (t *NamespacedTree) parityNamespace() []byte {
+	return bytes.Repeat([]byte{0xff}, t.namespaceSize)
+}
+
+func (t *NamespacedTree) nodeSize() int {
+	return 2*t.namespaceSize + sha256.Size
+}
+
+func (t *NamespacedTree) hashLeaf(ns []byte, share []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(ns)
+	h.Write(share)
+	node := make([]byte, 0, t.nodeSize())
+	node = append(node, ns...)
+	node = append(node, ns...)
+	return h.Sum(node)
+}
+
+func (t *NamespacedTree) hashNode(left []byte, right []byte) []byte {
+	n := t.namespaceSize
+	minNs, maxNs := left[:n], right[n:2*n]
+	// the maximum namespace ignores parity shares unless all leaves are
+	// parity shares
+	if bytes.Equal(right[:n], t.parityNamespace()) {
+		maxNs = left[n : 2*n]
+	}
+
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	node := make([]byte, 0, t.nodeSize())
+	node = append(node, minNs...)
+	node = append(node, maxNs...)
+	return h.Sum(node)
+}
+
+// emptyRoot returns the root of a tree without leaves.
+func (t *NamespacedTree) emptyRoot() []byte {
+	zero := make([]byte, t.namespaceSize)
+	hash := sha256.Sum256(nil)
+	node := make([]byte, 0, t.nodeSize())
+	node = append(node, zero...)
+	node = append(node, zero...)
+	return append(node, hash[:]...)
+}
@@ -0,0 +1,227 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNamespaceSize = 8
+
+func TestNamespacedTreeRoots(t *testing.T) {
+	eds, treeFn := createNamespacedTestEds(t, 4)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	parity := bytes.Repeat([]byte{0xff}, testNamespaceSize)
+	for i := uint(0); i < eds.Width(); i++ {
+		for _, tt := range []struct {
+			axis  Axis
+			root  []byte
+			cells [][]byte
+		}{
+			{Row, rowRoots[i], eds.Row(i)},
+			{Col, colRoots[i], eds.Col(i)},
+		} {
+			minNs, maxNs, err := NamespaceRange(tt.root, testNamespaceSize)
+			require.NoError(t, err)
+			if i >= eds.originalDataWidth {
+				assert.Equal(t, parity, minNs, "%s %d", tt.axis, i)
+				assert.Equal(t, parity, maxNs, "%s %d", tt.axis, i)
+			} else {
+				// the parity shares do not count towards the maximum namespace
+				assert.Equal(t, tt.cells[0][:testNamespaceSize], minNs, "%s %d", tt.axis, i)
+				assert.Equal(t, tt.cells[eds.originalDataWidth-1][:testNamespaceSize], maxNs, "%s %d", tt.axis, i)
+			}
+
+			tree := treeFn(tt.axis, i)
+			for _, cell := range tt.cells {
+				require.NoError(t, tree.Push(cell))
+			}
+			root, err := tree.Root()
+			require.NoError(t, err)
+			assert.Equal(t, tt.root, root)
+		}
+	}
+}
+
+func TestNamespacedTreeProofs(t *testing.T) {
+	eds, treeFn := createNamespacedTestEds(t, 2)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	for _, axis := range []Axis{Row, Col} {
+		for i := uint(0); i < eds.Width(); i++ {
+			for j := uint(0); j < eds.Width(); j++ {
+				proof, err := eds.ProveShare(axis, i, j)
+				require.NoError(t, err)
+				assert.NoError(t, proof.Verify(rowRoots, colRoots, treeFn))
+			}
+		}
+	}
+
+	proof, err := eds.ProveShare(Row, 0, 1)
+	require.NoError(t, err)
+	proof.Share[0]++
+	assert.ErrorIs(t, proof.Verify(rowRoots, colRoots, treeFn), ErrInvalidShareProof)
+
+	// a share of the original data cannot pose as a parity share
+	proof, err = eds.ProveShare(Row, 0, 1)
+	require.NoError(t, err)
+	proof.CellIndex = 2
+	assert.Error(t, proof.Verify(rowRoots, colRoots, treeFn))
+}
+
+func TestNamespacedTreeRepair(t *testing.T) {
+	eds, treeFn := createNamespacedTestEds(t, 4)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	flattened := eds.Flattened()
+	for i := range flattened {
+		if i%2 == 0 {
+			flattened[i] = nil
+		}
+	}
+	repaired, err := ImportExtendedDataSquare(flattened, NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+	require.NoError(t, repaired.Repair(rowRoots, colRoots))
+	assert.Equal(t, eds.squareRow, repaired.squareRow)
+}
+
+func TestNamespacedTreeByzantine(t *testing.T) {
+	// Row 0 has its namespaces out of order, while the columns are in order.
+	shares := [][]byte{
+		namespacedShare(1), namespacedShare(0),
+		namespacedShare(2), namespacedShare(3),
+	}
+	codec := NewLeoRSCodec()
+	treeFn := NewNamespacedTreeConstructor(testNamespaceSize, 2, 2)
+	eds, err := ComputeExtendedDataSquare(shares, codec, treeFn)
+	require.NoError(t, err)
+
+	// The producer commits to row 0 with a root that cannot be computed.
+	rowRoots := make([][]byte, eds.Height())
+	colRoots := make([][]byte, eds.Width())
+	for i := uint(0); i < eds.Width(); i++ {
+		colRoots[i], err = eds.getColRoot(i)
+		require.NoError(t, err)
+		if i == 0 {
+			_, err = eds.getRowRoot(i)
+			require.ErrorIs(t, err, ErrNamespaceOutOfOrder)
+			continue
+		}
+		rowRoots[i], err = eds.getRowRoot(i)
+		require.NoError(t, err)
+	}
+	rowRoots[0] = make([]byte, len(rowRoots[1]))
+
+	assertByzantineRow := func(t *testing.T, err error) {
+		var byzData *ErrByzantineData
+		require.ErrorAs(t, err, &byzData)
+		assert.Equal(t, Row, byzData.Axis)
+		assert.Equal(t, uint(0), byzData.Index)
+		require.NotNil(t, byzData.Proof)
+		assert.NoError(t, byzData.Proof.Verify(rowRoots, colRoots, codec, treeFn))
+	}
+
+	t.Run("complete", func(t *testing.T) {
+		imported, err := ImportExtendedDataSquare(eds.Flattened(), codec, treeFn)
+		require.NoError(t, err)
+		assertByzantineRow(t, imported.Repair(rowRoots, colRoots))
+	})
+
+	for solverName, opts := range solvers {
+		t.Run(solverName, func(t *testing.T) {
+			flattened := eds.Flattened()
+			flattened[1] = nil
+			imported, err := ImportExtendedDataSquare(flattened, codec, treeFn)
+			require.NoError(t, err)
+			assertByzantineRow(t, imported.Repair(rowRoots, colRoots, opts...))
+		})
+	}
+}
+
+func TestNamespacedTreePush(t *testing.T) {
+	treeFn := NewNamespacedTreeConstructor(testNamespaceSize, 2, 2)
+
+	t.Run("out of order", func(t *testing.T) {
+		tree := treeFn(Row, 0)
+		require.NoError(t, tree.Push(namespacedShare(2)))
+		assert.ErrorIs(t, tree.Push(namespacedShare(1)), ErrNamespaceOutOfOrder)
+	})
+
+	t.Run("parity shares in order", func(t *testing.T) {
+		tree := treeFn(Row, 0)
+		require.NoError(t, tree.Push(namespacedShare(2)))
+		require.NoError(t, tree.Push(namespacedShare(2)))
+		// parity shares are assigned the maximum namespace whatever they hold
+		require.NoError(t, tree.Push(namespacedShare(0)))
+		require.NoError(t, tree.Push(namespacedShare(1)))
+	})
+
+	t.Run("short share", func(t *testing.T) {
+		tree := treeFn(Row, 0)
+		assert.Error(t, tree.Push(make([]byte, testNamespaceSize-1)))
+	})
+
+	t.Run("empty tree", func(t *testing.T) {
+		root, err := treeFn(Col, 0).Root()
+		require.NoError(t, err)
+		minNs, maxNs, err := NamespaceRange(root, testNamespaceSize)
+		require.NoError(t, err)
+		assert.Equal(t, make([]byte, testNamespaceSize), minNs)
+		assert.Equal(t, make([]byte, testNamespaceSize), maxNs)
+	})
+
+	t.Run("invalid namespace size", func(t *testing.T) {
+		assert.Panics(t, func() { NewNamespacedTreeConstructor(0, 2, 2) })
+		_, _, err := NamespaceRange(make([]byte, 10), testNamespaceSize)
+		assert.Error(t, err)
+	})
+}
+
+func TestNamespacedTreeEncoding(t *testing.T) {
+	eds, treeFn := createNamespacedTestEds(t, 2)
+	data, err := Encoder{IncludeRoots: true}.Marshal(eds)
+	require.NoError(t, err)
+
+	decoded, err := Decoder{TreeConstructor: treeFn}.Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, eds.squareRow, decoded.squareRow)
+
+	_, err = Decoder{}.Unmarshal(data)
+	assert.Error(t, err)
+	_, err = Decoder{TreeConstructor: NewNamespacedTreeConstructor(4, 2, 2)}.Unmarshal(data)
+	assert.Error(t, err)
+}
+
+// createNamespacedTestEds extends a width x width square of shares with
+// namespaces ascending in both rows and columns.
+func createNamespacedTestEds(t *testing.T, width uint) (*ExtendedDataSquare, TreeConstructorFn) {
+	shares := make([][]byte, width*width)
+	for i := range shares {
+		shares[i] = namespacedShare(uint64(i))
+	}
+	treeFn := NewNamespacedTreeConstructor(testNamespaceSize, width, width)
+	eds, err := ComputeExtendedDataSquare(shares, NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+	return eds, treeFn
+}
+
+func namespacedShare(namespace uint64) []byte {
+	share := make([]byte, ShardSize)
+	binary.BigEndian.PutUint64(share, namespace)
+	_, _ = rand.Read(share[testNamespaceSize:])
+	return share
+}
@@ -0,0 +1,169 @@
+package rsmt2d
+
+import (
+	"context"
+	"sync"
+)
+
+// Executor runs tasks on behalf of an ExtendedDataSquare, e.g. on a shared
+// worker pool. Go must eventually run task, either on another goroutine or
+// before returning.
+type Executor interface {
+	Go(task func())
+}
+
+// Option configures how an ExtendedDataSquare parallelizes the extension,
+// root computation and repair of the square, and where it keeps its shares.
+type Option func(*options)
+
+type options struct {
+	// maxConcurrency is the maximum number of tasks that run at the same
+	// time. Zero means unlimited.
+	maxConcurrency int
+	// executor runs tasks. If nil, every task runs on a new goroutine.
+	executor Executor
+	// parallelSolver enables solveCrosswordParallel during repair.
+	parallelSolver bool
+	// collectByzantineData makes repair continue past Byzantine rows and
+	// columns.
+	collectByzantineData bool
+	// repairObserver is notified about repaired rows and columns.
+	repairObserver RepairObserver
+	// storage holds the shares of the square. If nil, every share is
+	// allocated separately.
+	storage Storage
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// with returns a copy of o with opts applied.
+func (o options) with(opts []Option) options {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithMaxConcurrency limits the number of tasks (e.g. encoding a row or
+// computing a root) that run at the same time to n. By default, or if n is
+// not positive, a goroutine is spawned for every row and column.
+func WithMaxConcurrency(n int) Option {
+	return func(o *options) {
+		if n < 0 {
+			n = 0
+		}
+		o.maxConcurrency = n
+	}
+}
+
+// WithExecutor runs tasks on the provided executor instead of spawning a
+// goroutine for each of them. It can be combined with WithMaxConcurrency.
+func WithExecutor(executor Executor) Option {
+	return func(o *options) {
+		o.executor = executor
+	}
+}
+
+// WithParallelSolver makes Repair solve the square in waves instead of one row
+// or column at a time: every iteration decodes all incomplete rows in
+// parallel and inserts them in the order of their indices, and then does the
+// same for the columns. Without Byzantine data, the repaired square is the
+// same as with the sequential solver, which alternates between rows and
+// columns. With Byzantine data, the returned error is that of the first
+// Byzantine row or column in the order of the waves, which may differ from
+// the one returned by the sequential solver. Since later rows and columns
+// can't benefit from shares repaired earlier in the same wave, the solver may
+// do more work in total than the sequential solver; it is faster if several
+// CPUs are available. The concurrency of the solver is limited by
+// WithMaxConcurrency.
+func WithParallelSolver() Option {
+	return func(o *options) {
+		o.parallelSolver = true
+	}
+}
+
+// WithCollectByzantineData makes Repair continue past rows and columns with
+// Byzantine data instead of returning the first ErrByzantineData. These rows
+// and columns are left unrepaired, every other row and column is repaired as
+// far as possible, and all Byzantine rows and columns are returned in an
+// ErrByzantineAxes.
+func WithCollectByzantineData() Option {
+	return func(o *options) {
+		o.collectByzantineData = true
+	}
+}
+
+// WithRepairObserver notifies observer about every row and column that is
+// repaired by Repair.
+func WithRepairObserver(observer RepairObserver) Option {
+	return func(o *options) {
+		o.repairObserver = observer
+	}
+}
+
+// workGroup runs tasks as configured by options and collects the first error
+// returned by a task, like errgroup.Group.
+type workGroup struct {
+	cancel   context.CancelFunc
+	executor Executor
+	// sem limits the number of running tasks. It is nil if unlimited.
+	sem chan struct{}
+
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+// newWorkGroup returns a workGroup and a context derived from ctx that is
+// canceled as soon as a task returns an error or Wait returns.
+func newWorkGroup(ctx context.Context, o options) (*workGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &workGroup{
+		cancel:   cancel,
+		executor: o.executor,
+	}
+	if o.maxConcurrency > 0 {
+		g.sem = make(chan struct{}, o.maxConcurrency)
+	}
+	return g, ctx
+}
+
+// Go runs task, blocking while the maximum number of tasks are running.
+func (g *workGroup) Go(task func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+
+	run := func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+		if err := task(); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}
+
+	if g.executor != nil {
+		g.executor.Go(run)
+	} else {
+		go run()
+	}
+}
+
+// Wait blocks until all tasks have returned and returns the first error.
+func (g *workGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
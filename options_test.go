@@ -0,0 +1,130 @@
+package rsmt2d
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingExecutor runs every task on a new goroutine and counts them.
+type countingExecutor struct {
+	tasks atomic.Int64
+}
+
+func (e *countingExecutor) Go(task func()) {
+	e.tasks.Add(1)
+	go task()
+}
+
+// concurrencyTracker records the maximum number of trees computing a root at
+// the same time.
+type concurrencyTracker struct {
+	active atomic.Int64
+	max    atomic.Int64
+}
+
+func (c *concurrencyTracker) newTree(axis Axis, index uint) Tree {
+	return &trackingTree{DefaultTree: NewDefaultTree(axis, index).(*DefaultTree), tracker: c}
+}
+
+type trackingTree struct {
+	*DefaultTree
+	tracker *concurrencyTracker
+}
+
+func (t *trackingTree) Root() ([]byte, error) {
+	active := t.tracker.active.Add(1)
+	defer t.tracker.active.Add(-1)
+	for {
+		max := t.tracker.max.Load()
+		if active <= max || t.tracker.max.CompareAndSwap(max, active) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	return t.DefaultTree.Root()
+}
+
+func TestWithMaxConcurrency(t *testing.T) {
+	for _, n := range []int{1, 2, 4} {
+		tracker := &concurrencyTracker{}
+		eds, err := ComputeExtendedDataSquare(genRandDS(8), NewLeoRSCodec(), tracker.newTree, WithMaxConcurrency(n))
+		require.NoError(t, err)
+
+		_, err = eds.RowRoots()
+		require.NoError(t, err)
+		assert.LessOrEqual(t, tracker.max.Load(), int64(n))
+		assert.Positive(t, tracker.max.Load())
+	}
+}
+
+func TestWithExecutor(t *testing.T) {
+	executor := &countingExecutor{}
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewLeoRSCodec(), NewDefaultTree, WithExecutor(executor), WithMaxConcurrency(2))
+	require.NoError(t, err)
+	// 4 rows and 4 columns of the original data, then 4 parity rows
+	assert.Equal(t, int64(12), executor.tasks.Load())
+
+	want, err := ComputeExtendedDataSquare(genRandDS(4), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	assert.Equal(t, want.Width(), eds.Width())
+
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+	assert.Equal(t, int64(12+16), executor.tasks.Load())
+
+	// options passed to Repair take precedence
+	repairExecutor := &countingExecutor{}
+	eds.setCell(0, 0, nil)
+	require.NoError(t, eds.Repair(rowRoots, colRoots, WithExecutor(repairExecutor)))
+	assert.Positive(t, repairExecutor.tasks.Load())
+	assert.Equal(t, int64(12+16), executor.tasks.Load())
+}
+
+func TestWorkGroup(t *testing.T) {
+	errTest := errors.New("test")
+
+	t.Run("returns first error and cancels context", func(t *testing.T) {
+		g, ctx := newWorkGroup(context.Background(), options{maxConcurrency: 1})
+		g.Go(func() error { return errTest })
+		var canceled bool
+		g.Go(func() error {
+			<-ctx.Done()
+			canceled = true
+			return nil
+		})
+		assert.ErrorIs(t, g.Wait(), errTest)
+		assert.True(t, canceled)
+	})
+
+	t.Run("limits concurrency", func(t *testing.T) {
+		var mu sync.Mutex
+		active, max := 0, 0
+		g, _ := newWorkGroup(context.Background(), options{maxConcurrency: 3})
+		for i := 0; i < 20; i++ {
+			g.Go(func() error {
+				mu.Lock()
+				active++
+				if active > max {
+					max = active
+				}
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				active--
+				mu.Unlock()
+				return nil
+			})
+		}
+		require.NoError(t, g.Wait())
+		assert.LessOrEqual(t, max, 3)
+	})
+}
@@ -0,0 +1,109 @@
+package rsmt2d
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTreeNotProvable is returned when an inclusion proof is requested from a
+// Tree that does not implement ProvableTree.
+var ErrTreeNotProvable = errors.New("tree does not support inclusion proofs")
+
+// ErrInvalidShareProof is returned when a ShareProof does not prove the
+// inclusion of its share in the expected root.
+var ErrInvalidShareProof = errors.New("invalid share proof")
+
+// ShareProof proves that a share is included in a row or column of an extended
+// data square, i.e. in one of its row or column roots.
+type ShareProof struct {
+	// Axis describes if the share is proven against a row or column root.
+	Axis Axis
+	// AxisIndex is the index of the row or column.
+	AxisIndex uint
+	// CellIndex is the index of the share within the row or column.
+	CellIndex uint
+	// Share is the proven share.
+	Share []byte
+	// Nodes are the Merkle proof nodes, as returned by ProvableTree.Prove.
+	Nodes [][]byte
+	// NumLeaves is the number of leaves of the tree, i.e. the length of the
+	// row or column.
+	NumLeaves uint
+}
+
+// Verify checks the proof against the row or column roots of an extended
+// data square, using a tree created by treeCreatorFn to verify the Merkle
+// proof. Returns nil if the proof is valid.
+func (p *ShareProof) Verify(rowRoots [][]byte, colRoots [][]byte, treeCreatorFn TreeConstructorFn) error {
+	roots := rowRoots
+	if p.Axis == Col {
+		roots = colRoots
+	}
+	if p.AxisIndex >= uint(len(roots)) {
+		return fmt.Errorf("%w: %s %d out of range", ErrInvalidShareProof, p.Axis, p.AxisIndex)
+	}
+	return p.VerifyRoot(roots[p.AxisIndex], treeCreatorFn)
+}
+
+// VerifyRoot checks the proof against the root of the row or column the share
+// belongs to. Returns nil if the proof is valid.
+func (p *ShareProof) VerifyRoot(root []byte, treeCreatorFn TreeConstructorFn) error {
+	tree, ok := treeCreatorFn(p.Axis, p.AxisIndex).(ProvableTree)
+	if !ok {
+		return ErrTreeNotProvable
+	}
+	if !tree.VerifyProof(root, p.Share, p.Nodes, p.CellIndex, p.NumLeaves) {
+		return fmt.Errorf("%w: share %d of %s %d", ErrInvalidShareProof, p.CellIndex, p.Axis, p.AxisIndex)
+	}
+	return nil
+}
+
+// ProveShare returns a proof of inclusion of the share at cellIndex in the row
+// or column at axisIndex, verifiable against the row or column roots of the
+// square. The row or column must be complete and the tree created by the
+// square's TreeConstructorFn must implement ProvableTree.
+func (eds *ExtendedDataSquare) ProveShare(axis Axis, axisIndex uint, cellIndex uint) (*ShareProof, error) {
+	var shares [][]byte
+	switch axis {
+	case Row:
+		if axisIndex >= eds.height || cellIndex >= eds.width {
+			return nil, fmt.Errorf("cell (%d, %d) is out of range", axisIndex, cellIndex)
+		}
+		shares = eds.row(axisIndex)
+	case Col:
+		if axisIndex >= eds.width || cellIndex >= eds.height {
+			return nil, fmt.Errorf("cell (%d, %d) is out of range", cellIndex, axisIndex)
+		}
+		shares = eds.col(axisIndex)
+	default:
+		return nil, fmt.Errorf("invalid axis type: %d", axis)
+	}
+	if !noMissingData(shares, noShareInsertion) {
+		return nil, fmt.Errorf("cannot prove share of incomplete %s %d", axis, axisIndex)
+	}
+
+	tree, ok := eds.createTreeFn(axis, axisIndex).(ProvableTree)
+	if !ok {
+		return nil, ErrTreeNotProvable
+	}
+	for _, d := range shares {
+		if err := tree.Push(d); err != nil {
+			return nil, err
+		}
+	}
+	nodes, err := tree.Prove(cellIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	share := make([]byte, len(shares[cellIndex]))
+	copy(share, shares[cellIndex])
+	return &ShareProof{
+		Axis:      axis,
+		AxisIndex: axisIndex,
+		CellIndex: cellIndex,
+		Share:     share,
+		Nodes:     nodes,
+		NumLeaves: uint(len(shares)),
+	}, nil
+}
@@ -0,0 +1,62 @@
+package rsmt2d
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveShare(t *testing.T) {
+	eds := createTestEds(NewLeoRSCodec(), ShardSize)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	for _, axis := range []Axis{Row, Col} {
+		for i := uint(0); i < eds.Width(); i++ {
+			for j := uint(0); j < eds.Width(); j++ {
+				proof, err := eds.ProveShare(axis, i, j)
+				require.NoError(t, err)
+				assert.NoError(t, proof.Verify(rowRoots, colRoots, NewDefaultTree))
+			}
+		}
+	}
+
+	t.Run("tampered share", func(t *testing.T) {
+		proof, err := eds.ProveShare(Row, 1, 2)
+		require.NoError(t, err)
+		proof.Share[0]++
+		assert.True(t, errors.Is(proof.Verify(rowRoots, colRoots, NewDefaultTree), ErrInvalidShareProof))
+	})
+
+	t.Run("wrong axis", func(t *testing.T) {
+		proof, err := eds.ProveShare(Row, 1, 2)
+		require.NoError(t, err)
+		proof.Axis = Col
+		assert.Error(t, proof.Verify(rowRoots, colRoots, NewDefaultTree))
+	})
+
+	t.Run("incomplete row", func(t *testing.T) {
+		incomplete := createTestEds(NewLeoRSCodec(), ShardSize)
+		incomplete.setCell(0, 0, nil)
+		_, err := incomplete.ProveShare(Row, 0, 1)
+		assert.Error(t, err)
+		_, err = incomplete.ProveShare(Row, 1, 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		_, err := eds.ProveShare(Col, 0, eds.Width())
+		assert.Error(t, err)
+	})
+
+	t.Run("tree without proofs", func(t *testing.T) {
+		square, err := ComputeExtendedDataSquare([][]byte{ones}, NewLeoRSCodec(), newErrorTree)
+		require.NoError(t, err)
+		_, err = square.ProveShare(Row, 0, 0)
+		assert.ErrorIs(t, err, ErrTreeNotProvable)
+	})
+}
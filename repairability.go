@@ -0,0 +1,277 @@
+package rsmt2d
+
+import "sort"
+
+// Cell identifies a cell of an ExtendedDataSquare by its row and column.
+type Cell struct {
+	Row uint
+	Col uint
+}
+
+// RepairabilityReport describes whether an ExtendedDataSquare can be repaired
+// with the shares it currently has.
+type RepairabilityReport struct {
+	// Repairable is true if Repair is able to complete the square, provided
+	// that none of its shares are Byzantine.
+	Repairable bool
+	// Unrecoverable contains the cells that are still missing once every row
+	// and column with enough shares has been repaired, in row-major order.
+	// Every row and column that intersects these cells has more missing cells
+	// than its codec can recover. It is empty if the square is repairable.
+	Unrecoverable []Cell
+	// Required is a subset of Unrecoverable that makes the square repairable
+	// once these cells are obtained, in row-major order. It is chosen
+	// greedily by repeatedly completing the row or column that lacks the
+	// fewest shares, so some of its cells may not be needed; see
+	// MinimalRequiredCells. It is empty if the square is repairable.
+	Required []Cell
+	// Iterations is the number of times Repair iterates over all rows and
+	// columns before the square is solved or no further progress is made.
+	Iterations int
+}
+
+// CanRepair returns true if Repair is able to complete the square, provided
+// that none of its shares are Byzantine. See AnalyzeRepairability.
+func (eds *ExtendedDataSquare) CanRepair() bool {
+	return eds.AnalyzeRepairability().Repairable
+}
+
+// AnalyzeRepairability determines whether the square can be repaired by
+// simulating the iterations of Repair on the pattern of missing shares,
+// without decoding any rows or columns or computing any roots. A row can be
+// repaired once it has at least as many shares as the original data is wide,
+// and a column once it has at least as many shares as the original data is
+// high.
+func (eds *ExtendedDataSquare) AnalyzeRepairability() RepairabilityReport {
+	sim := newRepairSimulation(eds)
+	var report RepairabilityReport
+	report.Repairable, report.Iterations = sim.run()
+	if report.Repairable {
+		return report
+	}
+	report.Unrecoverable = sim.missing()
+	report.Required = sortCells(sim.required())
+	return report
+}
+
+// MinimalRequiredCells returns a minimal set of missing cells that make the
+// square repairable once these cells are obtained, in row-major order:
+// leaving out any one of them makes the square unrepairable again. It starts
+// from the Required cells of AnalyzeRepairability and removes the cells that
+// are not needed, which takes a simulation of Repair per cell, so it is much
+// more expensive than AnalyzeRepairability for large squares. A different set
+// with fewer cells may exist. It returns nil if the square is repairable.
+func (eds *ExtendedDataSquare) MinimalRequiredCells() []Cell {
+	sim := newRepairSimulation(eds)
+	if solved, _ := sim.run(); solved {
+		return nil
+	}
+	return sortCells(sim.minimize(sim.required()))
+}
+
+// repairSimulation tracks which cells of a square are known while simulating
+// Repair.
+type repairSimulation struct {
+	eds      *ExtendedDataSquare
+	known    [][]bool
+	rowKnown []uint
+	colKnown []uint
+}
+
+func newRepairSimulation(eds *ExtendedDataSquare) *repairSimulation {
+	sim := &repairSimulation{
+		eds:      eds,
+		known:    make([][]bool, eds.height),
+		rowKnown: make([]uint, eds.height),
+		colKnown: make([]uint, eds.width),
+	}
+	for r := uint(0); r < eds.height; r++ {
+		sim.known[r] = make([]bool, eds.width)
+		for c := uint(0); c < eds.width; c++ {
+			if eds.squareRow[r][c] != nil {
+				sim.setKnown(r, c)
+			}
+		}
+	}
+	return sim
+}
+
+func (sim *repairSimulation) clone() *repairSimulation {
+	clone := &repairSimulation{
+		eds:      sim.eds,
+		known:    make([][]bool, len(sim.known)),
+		rowKnown: append([]uint(nil), sim.rowKnown...),
+		colKnown: append([]uint(nil), sim.colKnown...),
+	}
+	for r := range sim.known {
+		clone.known[r] = append([]bool(nil), sim.known[r]...)
+	}
+	return clone
+}
+
+func (sim *repairSimulation) setKnown(r, c uint) {
+	if !sim.known[r][c] {
+		sim.known[r][c] = true
+		sim.rowKnown[r]++
+		sim.colKnown[c]++
+	}
+}
+
+// run mirrors the iterations of solveCrossword. It returns whether the square
+// is solved and the number of iterations.
+func (sim *repairSimulation) run() (bool, int) {
+	eds := sim.eds
+	iterations := 0
+	for {
+		iterations++
+		// Track if the entire square is completely solved
+		solved := true
+		// Track if a single iteration of this loop made progress
+		progressMade := false
+
+		for i := uint(0); i < eds.width || i < eds.height; i++ {
+			if i < eds.height && sim.rowKnown[i] < eds.width {
+				if sim.rowKnown[i] >= eds.originalDataWidth {
+					for c := uint(0); c < eds.width; c++ {
+						sim.setKnown(i, c)
+					}
+					progressMade = true
+				} else {
+					solved = false
+				}
+			}
+			if i < eds.width && sim.colKnown[i] < eds.height {
+				if sim.colKnown[i] >= eds.originalDataHeight {
+					for r := uint(0); r < eds.height; r++ {
+						sim.setKnown(r, i)
+					}
+					progressMade = true
+				} else {
+					solved = false
+				}
+			}
+		}
+
+		if solved {
+			return true, iterations
+		}
+		if !progressMade {
+			return false, iterations
+		}
+	}
+}
+
+// missing returns the unknown cells in row-major order.
+func (sim *repairSimulation) missing() []Cell {
+	var missing []Cell
+	for r := uint(0); r < sim.eds.height; r++ {
+		for c := uint(0); c < sim.eds.width; c++ {
+			if !sim.known[r][c] {
+				missing = append(missing, Cell{Row: r, Col: c})
+			}
+		}
+	}
+	return missing
+}
+
+// required returns a set of unknown cells that make the square repairable,
+// for a simulation that has run without solving the square. Cells are added
+// by repeatedly unlocking the row or column that lacks the fewest shares.
+func (sim *repairSimulation) required() []Cell {
+	var required []Cell
+	for greedy := sim.clone(); ; {
+		cells := greedy.cheapestUnlock()
+		for _, cell := range cells {
+			greedy.setKnown(cell.Row, cell.Col)
+		}
+		required = append(required, cells...)
+		if solved, _ := greedy.run(); solved {
+			return required
+		}
+	}
+}
+
+// minimize removes the cells of required that are not needed to make the
+// square repairable, for a simulation that has run without solving the
+// square. Since repairing is monotonic, simulating from this state with extra
+// cells is the same as simulating from the square with these cells.
+func (sim *repairSimulation) minimize(required []Cell) []Cell {
+	for i := len(required) - 1; i >= 0; i-- {
+		candidate := append(append([]Cell(nil), required[:i]...), required[i+1:]...)
+		trial := sim.clone()
+		for _, cell := range candidate {
+			trial.setKnown(cell.Row, cell.Col)
+		}
+		if solved, _ := trial.run(); solved {
+			required = candidate
+		}
+	}
+	return required
+}
+
+// sortCells sorts cells in row-major order and returns them.
+func sortCells(cells []Cell) []Cell {
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Row != cells[j].Row {
+			return cells[i].Row < cells[j].Row
+		}
+		return cells[i].Col < cells[j].Col
+	})
+	return cells
+}
+
+// cheapestUnlock returns the fewest unknown cells that make a single
+// incomplete row or column repairable, preferring rows and lower indices on
+// ties. The simulation must have run without solving the square, so that
+// every incomplete row and column lacks shares. Within the row or column,
+// cells of the orthogonal columns or rows that lack the fewest shares are
+// chosen, so that they help to unlock those too.
+func (sim *repairSimulation) cheapestUnlock() []Cell {
+	eds := sim.eds
+	bestAxis, bestIndex, bestNeed := Row, uint(0), uint(0)
+	for r := uint(0); r < eds.height; r++ {
+		if sim.rowKnown[r] < eds.width {
+			if need := eds.originalDataWidth - sim.rowKnown[r]; bestNeed == 0 || need < bestNeed {
+				bestAxis, bestIndex, bestNeed = Row, r, need
+			}
+		}
+	}
+	for c := uint(0); c < eds.width; c++ {
+		if sim.colKnown[c] < eds.height {
+			if need := eds.originalDataHeight - sim.colKnown[c]; bestNeed == 0 || need < bestNeed {
+				bestAxis, bestIndex, bestNeed = Col, c, need
+			}
+		}
+	}
+
+	var candidates []Cell
+	var needs []uint
+	if bestAxis == Row {
+		for c := uint(0); c < eds.width; c++ {
+			if !sim.known[bestIndex][c] {
+				candidates = append(candidates, Cell{Row: bestIndex, Col: c})
+				needs = append(needs, eds.originalDataHeight-sim.colKnown[c])
+			}
+		}
+	} else {
+		for r := uint(0); r < eds.height; r++ {
+			if !sim.known[r][bestIndex] {
+				candidates = append(candidates, Cell{Row: r, Col: bestIndex})
+				needs = append(needs, eds.originalDataWidth-sim.rowKnown[r])
+			}
+		}
+	}
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return needs[order[i]] < needs[order[j]]
+	})
+
+	cells := make([]Cell, bestNeed)
+	for i := range cells {
+		cells[i] = candidates[order[i]]
+	}
+	return cells
+}
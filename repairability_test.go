@@ -0,0 +1,156 @@
+package rsmt2d
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeRepairability(t *testing.T) {
+	shareSize := 64
+	original := createTestEds(NewLeoRSCodec(), shareSize)
+
+	t.Run("complete", func(t *testing.T) {
+		report := original.AnalyzeRepairability()
+		assert.Equal(t, RepairabilityReport{Repairable: true, Iterations: 1}, report)
+		assert.True(t, original.CanRepair())
+		assert.Nil(t, original.MinimalRequiredCells())
+	})
+
+	t.Run("maximum erasures", func(t *testing.T) {
+		eds, err := original.deepCopy()
+		require.NoError(t, err)
+		for _, i := range []uint{0, 2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 13} {
+			eds.setCell(i/4, i%4, nil)
+		}
+
+		report := eds.AnalyzeRepairability()
+		assert.True(t, report.Repairable)
+		assert.Empty(t, report.Unrecoverable)
+		assert.Empty(t, report.Required)
+		assert.True(t, eds.CanRepair())
+		assert.Nil(t, eds.MinimalRequiredCells())
+	})
+
+	t.Run("unrepairable", func(t *testing.T) {
+		eds, err := original.deepCopy()
+		require.NoError(t, err)
+		// Only the shares at (0, 3) and (1, 3) are left. Column 3 can be
+		// repaired, which leaves a single share in every row.
+		for i := uint(0); i < 16; i++ {
+			if i != 3 && i != 7 {
+				eds.setCell(i/4, i%4, nil)
+			}
+		}
+
+		report := eds.AnalyzeRepairability()
+		assert.False(t, report.Repairable)
+		assert.False(t, eds.CanRepair())
+		assert.Equal(t, []Cell{
+			{0, 0}, {0, 1}, {0, 2},
+			{1, 0}, {1, 1}, {1, 2},
+			{2, 0}, {2, 1}, {2, 2},
+			{3, 0}, {3, 1}, {3, 2},
+		}, report.Unrecoverable)
+		// One more share in each of two rows repairs those rows, after which
+		// every column has enough shares.
+		assert.Equal(t, []Cell{{0, 0}, {1, 0}}, report.Required)
+		assert.Equal(t, []Cell{{0, 0}, {1, 0}}, eds.MinimalRequiredCells())
+		assert.Equal(t, 2, report.Iterations)
+	})
+}
+
+// TestAnalyzeRepairabilityMatchesRepair checks that the analysis agrees with
+// the outcome of Repair for random patterns of missing shares.
+func TestAnalyzeRepairabilityMatchesRepair(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+
+	width := int(original.Width())
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 200; n++ {
+		flattened := original.Flattened()
+		for _, i := range rng.Perm(width * width)[:width*width/4+rng.Intn(width*width/2)] {
+			flattened[i] = nil
+		}
+		eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+		require.NoError(t, err)
+
+		report := eds.AnalyzeRepairability()
+		err = eds.Repair(rowRoots, colRoots)
+		if report.Repairable {
+			require.NoError(t, err)
+			continue
+		}
+		require.ErrorIs(t, err, ErrUnrepairableDataSquare)
+
+		// Repair leaves exactly the unrecoverable cells missing.
+		var missing []Cell
+		for r := uint(0); r < eds.Height(); r++ {
+			for c := uint(0); c < eds.Width(); c++ {
+				if eds.GetCell(r, c) == nil {
+					missing = append(missing, Cell{r, c})
+				}
+			}
+		}
+		assert.Equal(t, missing, report.Unrecoverable)
+
+		// The required cells make the square repairable, and none of the
+		// minimal required cells can be left out.
+		require.NotEmpty(t, report.Required)
+		assert.Subset(t, report.Unrecoverable, report.Required)
+		withCells := func(cells []Cell, skip int) *ExtendedDataSquare {
+			repaired := make([][]byte, len(flattened))
+			copy(repaired, flattened)
+			for i, cell := range cells {
+				if i != skip {
+					repaired[cell.Row*uint(width)+cell.Col] = original.GetCell(cell.Row, cell.Col)
+				}
+			}
+			eds, err := ImportExtendedDataSquare(repaired, codec, NewDefaultTree)
+			require.NoError(t, err)
+			return eds
+		}
+		completed := withCells(report.Required, -1)
+		require.True(t, completed.CanRepair())
+		require.NoError(t, completed.Repair(rowRoots, colRoots))
+
+		minimal := eds.MinimalRequiredCells()
+		assert.Subset(t, report.Required, minimal)
+		require.True(t, withCells(minimal, -1).CanRepair())
+		for i := range minimal {
+			assert.False(t, withCells(minimal, i).CanRepair())
+		}
+	}
+}
+
+func BenchmarkAnalyzeRepairability(b *testing.B) {
+	for originalDataWidth := 32; originalDataWidth <= 256; originalDataWidth *= 2 {
+		// Keep a fifth of the shares, which is too few to repair the square.
+		width := 2 * originalDataWidth
+		data := make([][]byte, width*width)
+		share := make([]byte, 64)
+		rng := rand.New(rand.NewSource(1))
+		for i := range data {
+			if rng.Intn(5) == 0 {
+				data[i] = share
+			}
+		}
+		eds, err := ImportExtendedDataSquare(data, NewLeoRSCodec(), NewDefaultTree)
+		require.NoError(b, err)
+
+		b.Run(fmt.Sprintf("%dx%d ODS", originalDataWidth, originalDataWidth), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				eds.AnalyzeRepairability()
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package rsmt2d
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// RepairEvent describes a row or column that was repaired.
+type RepairEvent struct {
+	// Axis describes if the repaired vector is a row or column.
+	Axis Axis
+	// Index is the row or column index.
+	Index uint
+	// SharesFilled is the number of missing shares that were filled.
+	SharesFilled int
+	// Iteration is the iteration of the crossword solver in which the row or
+	// column was repaired, starting at 1.
+	Iteration int
+}
+
+// RepairObserver is notified about every row and column that Repair repairs.
+// Its methods are never called concurrently during a single repair.
+type RepairObserver interface {
+	ObserveRepair(event RepairEvent)
+}
+
+// RepairObserverFunc is an adapter to use an ordinary function as a
+// RepairObserver.
+type RepairObserverFunc func(event RepairEvent)
+
+// ObserveRepair calls f(event).
+func (f RepairObserverFunc) ObserveRepair(event RepairEvent) {
+	f(event)
+}
+
+// RepairStats summarizes the work done by a repair.
+type RepairStats struct {
+	// Iterations is the number of times the crossword solver iterated over
+	// the rows and columns of the square.
+	Iterations int
+	// DecodesAttempted is the number of rows and columns that were decoded.
+	DecodesAttempted int
+	// DecodesSucceeded is the number of rows and columns that had enough
+	// shares to be decoded.
+	DecodesSucceeded int
+	// SharesRecovered is the number of missing shares that were filled.
+	SharesRecovered int
+	// RootComputations is the number of row and column roots that were
+	// computed to verify shares.
+	RootComputations int
+	// SanityCheckDuration is the time spent verifying the complete rows and
+	// columns before solving.
+	SanityCheckDuration time.Duration
+	// SolveDuration is the time spent solving the crossword.
+	SolveDuration time.Duration
+}
+
+// repairStats records the progress of a repair. A nil *repairStats records
+// nothing.
+type repairStats struct {
+	observer RepairObserver
+
+	iteration        atomic.Int64
+	decodesAttempted atomic.Int64
+	decodesSucceeded atomic.Int64
+	sharesRecovered  atomic.Int64
+	rootComputations atomic.Int64
+
+	sanityCheckDuration time.Duration
+	solveDuration       time.Duration
+}
+
+func (s *repairStats) startIteration() {
+	if s != nil {
+		s.iteration.Add(1)
+	}
+}
+
+func (s *repairStats) decoded(isDecoded bool) {
+	if s == nil {
+		return
+	}
+	s.decodesAttempted.Add(1)
+	if isDecoded {
+		s.decodesSucceeded.Add(1)
+	}
+}
+
+func (s *repairStats) rootComputed() {
+	if s != nil {
+		s.rootComputations.Add(1)
+	}
+}
+
+// add records the decodes and root computations recorded by other.
+func (s *repairStats) add(other *repairStats) {
+	if s == nil {
+		return
+	}
+	s.decodesAttempted.Add(other.decodesAttempted.Load())
+	s.decodesSucceeded.Add(other.decodesSucceeded.Load())
+	s.rootComputations.Add(other.rootComputations.Load())
+}
+
+// repaired records that sharesFilled shares of a row or column were filled
+// and notifies the observer.
+func (s *repairStats) repaired(axis Axis, index uint, sharesFilled int) {
+	if s == nil {
+		return
+	}
+	s.sharesRecovered.Add(int64(sharesFilled))
+	if s.observer != nil {
+		s.observer.ObserveRepair(RepairEvent{
+			Axis:         axis,
+			Index:        index,
+			SharesFilled: sharesFilled,
+			Iteration:    int(s.iteration.Load()),
+		})
+	}
+}
+
+func (s *repairStats) summary() RepairStats {
+	return RepairStats{
+		Iterations:          int(s.iteration.Load()),
+		DecodesAttempted:    int(s.decodesAttempted.Load()),
+		DecodesSucceeded:    int(s.decodesSucceeded.Load()),
+		SharesRecovered:     int(s.sharesRecovered.Load()),
+		RootComputations:    int(s.rootComputations.Load()),
+		SanityCheckDuration: s.sanityCheckDuration,
+		SolveDuration:       s.solveDuration,
+	}
+}
@@ -0,0 +1,76 @@
+package rsmt2d
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairWithStats(t *testing.T) {
+	codec := NewLeoRSCodec()
+	original := createTestEds(codec, 64)
+	rowRoots, err := original.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := original.ColRoots()
+	require.NoError(t, err)
+
+	// the maximum erasures of TestRepairExtendedDataSquare
+	erased := []int{0, 2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 13}
+
+	for solverName, opts := range solvers {
+		t.Run(solverName, func(t *testing.T) {
+			flattened := original.Flattened()
+			for _, i := range erased {
+				flattened[i] = nil
+			}
+			eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+			require.NoError(t, err)
+
+			var events []RepairEvent
+			observer := RepairObserverFunc(func(event RepairEvent) {
+				events = append(events, event)
+			})
+			stats, err := eds.RepairWithStats(context.Background(), rowRoots, colRoots, append(opts, WithRepairObserver(observer))...)
+			require.NoError(t, err)
+			assert.Equal(t, original.Flattened(), eds.Flattened())
+
+			assert.Equal(t, len(erased), stats.SharesRecovered)
+			assert.Equal(t, len(events), stats.DecodesSucceeded)
+			assert.GreaterOrEqual(t, stats.DecodesAttempted, stats.DecodesSucceeded)
+			assert.GreaterOrEqual(t, stats.RootComputations, stats.DecodesSucceeded)
+			assert.Positive(t, stats.Iterations)
+
+			sharesFilled := 0
+			for i, event := range events {
+				sharesFilled += event.SharesFilled
+				assert.Positive(t, event.SharesFilled)
+				assert.Positive(t, event.Iteration)
+				assert.LessOrEqual(t, event.Iteration, stats.Iterations)
+				if i > 0 {
+					assert.GreaterOrEqual(t, event.Iteration, events[i-1].Iteration)
+				}
+			}
+			assert.Equal(t, len(erased), sharesFilled)
+		})
+	}
+
+	t.Run("unrepairable", func(t *testing.T) {
+		flattened := original.Flattened()
+		for i := range flattened[:12] {
+			flattened[i] = nil
+		}
+		eds, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+		require.NoError(t, err)
+
+		stats, err := eds.RepairWithStats(context.Background(), rowRoots, colRoots)
+		assert.ErrorIs(t, err, ErrUnrepairableDataSquare)
+		// Only the last row is left, so decoding the other 3 rows and the 4
+		// columns fails in the first iteration.
+		assert.Equal(t, 1, stats.Iterations)
+		assert.Equal(t, 3+4, stats.DecodesAttempted)
+		assert.Equal(t, 0, stats.DecodesSucceeded)
+		assert.Equal(t, 0, stats.SharesRecovered)
+	})
+}
@@ -0,0 +1,143 @@
+package rsmt2d
+
+import (
+	"fmt"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+var (
+	_ ExtensionFactorCodec = &RSGF8Codec{}
+	_ EncodeIntoCodec      = &RSGF8Codec{}
+	_ DecodeIntoCodec      = &RSGF8Codec{}
+)
+
+func init() {
+	registerCodec(RSGF8, NewRSGF8Codec())
+}
+
+// rsgf8MaxShards is the maximum number of data + parity shards supported by
+// a Reed-Solomon code over GF(2^8).
+const rsgf8MaxShards = 256
+
+// RSGF8Codec is a classic Reed-Solomon codec over GF(2^8) backed by a
+// systematic Vandermonde encoding matrix. Unlike the Leopard codec, it does
+// not require shares to be a multiple of 64 bytes, but it only supports up to
+// 256 shards (original + parity) per row or column.
+type RSGF8Codec struct {
+	cfg codecConfig
+
+	// Cache the encoders of various sizes to not have to re-instantiate those
+	// as it is costly. The least recently used encoders are evicted once the
+	// cache holds cfg.encoderCacheSize encoders.
+	encCache encoderCache
+}
+
+func (c *RSGF8Codec) Encode(data [][]byte) ([][]byte, error) {
+	enc, parityLen, err := c.encoderFor(len(data))
+	if err != nil {
+		return nil, err
+	}
+	parity := newParityShares(parityLen, len(data[0]))
+	if err := encodeShards(enc, data, parity); err != nil {
+		return nil, err
+	}
+	return parity, nil
+}
+
+// EncodeInto encodes data like Encode, but writes the parity shares into
+// parity.
+func (c *RSGF8Codec) EncodeInto(data [][]byte, parity [][]byte) error {
+	enc, parityLen, err := c.encoderFor(len(data))
+	if err != nil {
+		return err
+	}
+	if len(parity) != parityLen {
+		return fmt.Errorf("expected %d parity shares, got %d", parityLen, len(parity))
+	}
+	return encodeShards(enc, data, parity)
+}
+
+// encoderFor returns the encoder for dataLen original shares and the number
+// of parity shares it computes.
+func (c *RSGF8Codec) encoderFor(dataLen int) (reedsolomon.Encoder, int, error) {
+	dataLen, parityLen, err := c.cfg.shardCounts(dataLen * int(c.cfg.factor()))
+	if err != nil {
+		return nil, 0, err
+	}
+	enc, err := c.loadOrInitEncoder(dataLen, parityLen)
+	return enc, parityLen, err
+}
+
+func (c *RSGF8Codec) Decode(data [][]byte) ([][]byte, error) {
+	dataLen, parityLen, err := c.cfg.shardCounts(len(data))
+	if err != nil {
+		return nil, err
+	}
+	enc, err := c.loadOrInitEncoder(dataLen, parityLen)
+	if err != nil {
+		return nil, err
+	}
+	err = enc.Reconstruct(data)
+	return data, err
+}
+
+// DecodeInto decodes data like Decode, but reconstructs every missing share
+// data[i] into buffers[i] and sets data[i] to it.
+func (c *RSGF8Codec) DecodeInto(data [][]byte, buffers [][]byte) error {
+	dataLen, parityLen, err := c.cfg.shardCounts(len(data))
+	if err != nil {
+		return err
+	}
+	enc, err := c.loadOrInitEncoder(dataLen, parityLen)
+	if err != nil {
+		return err
+	}
+	return reconstructInto(enc, data, buffers)
+}
+
+func (c *RSGF8Codec) loadOrInitEncoder(dataLen int, parityLen int) (reedsolomon.Encoder, error) {
+	// reedsolomon.New silently switches to Leopard GF(2^16) for more than 256
+	// shards, so reject those sizes explicitly.
+	if dataLen+parityLen > rsgf8MaxShards {
+		return nil, fmt.Errorf("%s codec supports at most %d shards, got %d", RSGF8, rsgf8MaxShards, dataLen+parityLen)
+	}
+	return c.encCache.get(dataLen, func() (reedsolomon.Encoder, error) {
+		return reedsolomon.New(dataLen, parityLen)
+	})
+}
+
+// PrewarmEncoders creates and caches the encoders for original data of the
+// provided widths. See LeoRSCodec.PrewarmEncoders.
+func (c *RSGF8Codec) PrewarmEncoders(widths ...uint) error {
+	for _, width := range widths {
+		if _, _, err := c.encoderFor(int(width)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncoderCacheStats returns the usage of the encoder cache of the codec.
+func (c *RSGF8Codec) EncoderCacheStats() EncoderCacheStats {
+	return c.encCache.stats()
+}
+
+func (c *RSGF8Codec) MaxChunks() int {
+	maxDataLen := rsgf8MaxShards / int(c.cfg.factor())
+	return maxDataLen * maxDataLen
+}
+
+func (c *RSGF8Codec) Name() string {
+	return c.cfg.name(RSGF8)
+}
+
+// ExtensionFactor returns the ratio of extended to original shares.
+func (c *RSGF8Codec) ExtensionFactor() uint {
+	return c.cfg.factor()
+}
+
+func NewRSGF8Codec(opts ...CodecOption) *RSGF8Codec {
+	cfg := newCodecConfig(opts)
+	return &RSGF8Codec{cfg: cfg, encCache: encoderCache{capacity: cfg.encoderCacheSize}}
+}
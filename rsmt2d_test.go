@@ -18,6 +18,7 @@ func TestEdsRepairRoundtripSimple(t *testing.T) {
 		codec     rsmt2d.Codec
 	}{
 		{"leopard", bufferSize, rsmt2d.NewLeoRSCodec()},
+		{"rsgf8", bufferSize, rsmt2d.NewRSGF8Codec()},
 	}
 
 	for _, tt := range tests {
@@ -87,6 +88,7 @@ func TestEdsRepairTwice(t *testing.T) {
 		codec     rsmt2d.Codec
 	}{
 		{"leopard", bufferSize, rsmt2d.NewLeoRSCodec()},
+		{"rsgf8", bufferSize, rsmt2d.NewRSGF8Codec()},
 	}
 
 	for _, tt := range tests {
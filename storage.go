@@ -0,0 +1,137 @@
+package rsmt2d
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Storage provides the memory in which an ExtendedDataSquare keeps its shares,
+// e.g. a memory-mapped file (see MappedFile). The shares are laid out in
+// row-major order: the share in row r and column c of a square of width w
+// occupies the bytes [(r*w+c)*s, (r*w+c+1)*s) of Bytes, where s is the share
+// size.
+type Storage interface {
+	// Bytes returns the memory of the storage. It must hold at least
+	// width*height*shareSize bytes of the extended square.
+	Bytes() []byte
+}
+
+// WithStorage keeps the bytes of the shares of a square computed or imported
+// by ComputeExtendedDataSquare, ComputeExtendedDataRectangle,
+// ImportExtendedDataSquare or ImportExtendedDataRectangle in storage instead
+// of allocating every share separately. The shares passed to these functions
+// and the shares set later, e.g. by SetCell or Repair, are copied into
+// storage. Codecs implementing EncodeIntoCodec and DecodeIntoCodec encode
+// parity shares and repair missing shares directly into storage. The bytes of
+// missing shares are unspecified.
+//
+// Only the bytes of the shares live in storage. The square still keeps an
+// index of its rows and an index of its columns on the heap, with a slice
+// header pointing into storage for every share in each of them, i.e. 48 bytes
+// per share on 64-bit platforms regardless of the share size. Columns are
+// served from the column index, not by strided reads of storage. Row, Col and
+// GetCell return copies of the shares, while Flattened returns slices of
+// storage. Shares of zero bytes cannot be kept in storage.
+//
+// The square must not be used after the memory of storage is released, and
+// storage must not be shared by squares that are used at the same time.
+func WithStorage(storage Storage) Option {
+	return func(o *options) {
+		o.storage = storage
+	}
+}
+
+// errEmptyStoredShare is returned for shares of zero bytes set in a square
+// configured with WithStorage.
+var errEmptyStoredShare = errors.New("cannot keep shares of zero bytes in storage")
+
+// useStorage moves the shares of the square into the storage configured by
+// WithStorage, if any, laid out for a square of width by height shares.
+func (ds *dataSquare) useStorage(width uint, height uint) error {
+	if ds.opts.storage == nil {
+		return nil
+	}
+	// the share size of a square without shares is set by its first share
+	if ds.chunkSize == 0 {
+		for _, row := range ds.squareRow {
+			for _, chunk := range row {
+				if chunk != nil {
+					return errEmptyStoredShare
+				}
+			}
+		}
+	}
+	return ds.useBuffer(ds.opts.storage.Bytes(), width, height)
+}
+
+// byteStorage is a Storage backed by a byte slice.
+type byteStorage []byte
+
+func (s byteStorage) Bytes() []byte {
+	return s
+}
+
+// ComputeExtendedDataSquareFromBuffer computes the extended data square for
+// original data given as a single buffer of shares of shareSize bytes in
+// row-major order. Unless WithStorage is given, the extended square is kept in
+// a single newly allocated buffer, into which the parity shares are encoded in
+// place by the codecs shipped with rsmt2d.
+func ComputeExtendedDataSquareFromBuffer(
+	data []byte,
+	shareSize uint,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...Option,
+) (*ExtendedDataSquare, error) {
+	if err := validateExtensionFactor(codec); err != nil {
+		return nil, err
+	}
+	shares, err := splitShares(data, shareSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) > codec.MaxChunks() {
+		return nil, errors.New("number of chunks exceeds the maximum")
+	}
+	if newOptions(opts).storage == nil {
+		factor := extensionFactor(codec)
+		buf := make([]byte, uint(len(data))*factor*factor)
+		opts = append([]Option{WithStorage(byteStorage(buf))}, opts...)
+	}
+	return ComputeExtendedDataSquare(shares, codec, treeCreatorFn, opts...)
+}
+
+// ImportExtendedDataSquareFromBuffer imports an extended data square given as
+// a single buffer of shares of shareSize bytes in row-major order. Unless
+// WithStorage is given, the square keeps its shares in data without copying
+// them, so data must not be modified while the square is used.
+func ImportExtendedDataSquareFromBuffer(
+	data []byte,
+	shareSize uint,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...Option,
+) (*ExtendedDataSquare, error) {
+	shares, err := splitShares(data, shareSize)
+	if err != nil {
+		return nil, err
+	}
+	return ImportExtendedDataSquare(shares, codec, treeCreatorFn, append([]Option{WithStorage(byteStorage(data))}, opts...)...)
+}
+
+// splitShares splits data into shares of shareSize bytes that are slices of
+// data.
+func splitShares(data []byte, shareSize uint) ([][]byte, error) {
+	if shareSize == 0 {
+		return nil, errors.New("share size must be positive")
+	}
+	if uint(len(data))%shareSize != 0 {
+		return nil, fmt.Errorf("buffer of %d bytes is not a multiple of the share size %d", len(data), shareSize)
+	}
+	shares := make([][]byte, uint(len(data))/shareSize)
+	for i := range shares {
+		offset := uint(i) * shareSize
+		shares[i] = data[offset : offset+shareSize : offset+shareSize]
+	}
+	return shares, nil
+}
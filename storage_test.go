@@ -0,0 +1,232 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStorage(t *testing.T) {
+	for codecName, codec := range codecs {
+		t.Run(codecName, func(t *testing.T) {
+			want, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+			require.NoError(t, err)
+			wantRoots, err := want.RowRoots()
+			require.NoError(t, err)
+
+			storage := make(byteStorage, 8*8*256)
+			ods := make([][]byte, 0, 16)
+			for r := uint(0); r < 4; r++ {
+				ods = append(ods, want.Row(r)[:4]...)
+			}
+			eds, err := ComputeExtendedDataSquare(ods, codec, NewDefaultTree, WithStorage(storage))
+			require.NoError(t, err)
+			assert.Equal(t, want.squareRow, eds.squareRow)
+			assert.Equal(t, flattenChunks(want.Flattened()), []byte(storage))
+			assertStored(t, eds, storage)
+
+			roots, err := eds.RowRoots()
+			require.NoError(t, err)
+			assert.Equal(t, wantRoots, roots)
+		})
+	}
+}
+
+func TestWithStorageRepair(t *testing.T) {
+	want := createTestEds(NewLeoRSCodec(), ShardSize)
+	rowRoots, err := want.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := want.ColRoots()
+	require.NoError(t, err)
+
+	flattened := want.Flattened()
+	for _, i := range []int{0, 2, 3, 5, 9, 10, 15} {
+		flattened[i] = nil
+	}
+	storage := make(byteStorage, 16*ShardSize)
+	eds, err := ImportExtendedDataSquare(flattened, want.codec, NewDefaultTree, WithStorage(storage))
+	require.NoError(t, err)
+	assertStored(t, eds, storage)
+
+	require.NoError(t, eds.Repair(rowRoots, colRoots))
+	assert.Equal(t, want.squareRow, eds.squareRow)
+	assert.Equal(t, flattenChunks(want.Flattened()), []byte(storage))
+	assertStored(t, eds, storage)
+}
+
+func TestWithStorageSetCell(t *testing.T) {
+	storage := make(byteStorage, 16*ShardSize)
+	eds, err := ImportExtendedDataSquare(make([][]byte, 16), NewLeoRSCodec(), NewDefaultTree, WithStorage(storage))
+	require.NoError(t, err)
+
+	// an empty square takes the size of the first share
+	eds.chunkSize = ShardSize
+	share := make([]byte, ShardSize)
+	share[0] = 1
+	require.NoError(t, eds.SetCell(1, 2, share))
+	share[0] = 2
+	assert.Equal(t, byte(1), eds.GetCell(1, 2)[0])
+	assert.Equal(t, byte(1), storage[(1*4+2)*ShardSize])
+
+	// the storage is too small for larger shares
+	eds.chunkSize = 2 * ShardSize
+	assert.Error(t, eds.SetCell(0, 0, make([]byte, 2*ShardSize)))
+}
+
+func TestWithStorageRectangle(t *testing.T) {
+	rowCodec := NewLeoRSCodec()
+	colCodec := NewLeoRSCodec(WithExtensionFactor(4))
+	want, err := ComputeExtendedDataRectangle(genRandDS(2)[:2], 1, rowCodec, colCodec, NewDefaultTree)
+	require.NoError(t, err)
+
+	storage := make(byteStorage, 4*4*256)
+	eds, err := ComputeExtendedDataRectangle(want.Row(0)[:2], 1, rowCodec, colCodec, NewDefaultTree, WithStorage(storage))
+	require.NoError(t, err)
+	assert.Equal(t, want.squareRow, eds.squareRow)
+	assert.Equal(t, flattenChunks(want.Flattened()), []byte(storage))
+}
+
+func TestWithStorageTooSmall(t *testing.T) {
+	storage := make(byteStorage, 15*ShardSize)
+	_, err := ComputeExtendedDataSquare(createTestEds(NewLeoRSCodec(), ShardSize).Flattened()[:4], NewLeoRSCodec(), NewDefaultTree, WithStorage(storage))
+	assert.Error(t, err)
+	_, err = ImportExtendedDataSquare(createTestEds(NewLeoRSCodec(), ShardSize).Flattened(), NewLeoRSCodec(), NewDefaultTree, WithStorage(storage))
+	assert.Error(t, err)
+}
+
+func TestWithStorageZeroShareSize(t *testing.T) {
+	storage := make(byteStorage, 16*ShardSize)
+	empty := make([][]byte, 16)
+	empty[5] = []byte{}
+	_, err := ImportExtendedDataSquare(empty, NewLeoRSCodec(), NewDefaultTree, WithStorage(storage))
+	assert.ErrorIs(t, err, errEmptyStoredShare)
+
+	// a square without shares takes the size of its first share
+	eds, err := ImportExtendedDataSquare(make([][]byte, 16), NewLeoRSCodec(), NewDefaultTree, WithStorage(storage))
+	require.NoError(t, err)
+	assert.ErrorIs(t, eds.SetCell(0, 0, []byte{}), errEmptyStoredShare)
+}
+
+// assertStored asserts that every share of eds is kept at its position in
+// storage.
+func assertStored(t *testing.T, eds *ExtendedDataSquare, storage []byte) {
+	t.Helper()
+	for r := uint(0); r < eds.height; r++ {
+		for c := uint(0); c < eds.width; c++ {
+			share := eds.squareRow[r][c]
+			if share == nil {
+				continue
+			}
+			offset := (r*eds.width + c) * eds.chunkSize
+			assert.Same(t, &storage[offset], &share[0], "share (%d, %d)", r, c)
+			assert.Same(t, &share[0], &eds.squareCol[c][r][0], "share (%d, %d)", r, c)
+		}
+	}
+}
+
+func TestComputeExtendedDataSquareFromBuffer(t *testing.T) {
+	for codecName, codec := range codecs {
+		t.Run(codecName, func(t *testing.T) {
+			ods := genRandDS(4)
+			want, err := ComputeExtendedDataSquare(ods, codec, NewDefaultTree)
+			require.NoError(t, err)
+
+			data := flattenChunks(ods)
+			eds, err := ComputeExtendedDataSquareFromBuffer(data, 256, codec, NewDefaultTree)
+			require.NoError(t, err)
+			assert.Equal(t, want.squareRow, eds.squareRow)
+			assertStored(t, eds, eds.buf)
+			assert.Equal(t, flattenChunks(ods), data)
+
+			storage := make(byteStorage, 8*8*256)
+			eds, err = ComputeExtendedDataSquareFromBuffer(data, 256, codec, NewDefaultTree, WithStorage(storage))
+			require.NoError(t, err)
+			assert.Equal(t, want.squareRow, eds.squareRow)
+			assertStored(t, eds, storage)
+		})
+	}
+
+	t.Run("allocations", func(t *testing.T) {
+		ods := genRandDS(16)
+		data := flattenChunks(ods)
+		allocs := testing.AllocsPerRun(10, func() {
+			_, err := ComputeExtendedDataSquare(ods, NewLeoRSCodec(), NewDefaultTree)
+			require.NoError(t, err)
+		})
+		bufferAllocs := testing.AllocsPerRun(10, func() {
+			_, err := ComputeExtendedDataSquareFromBuffer(data, 256, NewLeoRSCodec(), NewDefaultTree)
+			require.NoError(t, err)
+		})
+		assert.Less(t, bufferAllocs, allocs)
+	})
+}
+
+func TestImportExtendedDataSquareFromBuffer(t *testing.T) {
+	want := createTestEds(NewLeoRSCodec(), ShardSize)
+	wantRoots, err := want.RowRoots()
+	require.NoError(t, err)
+
+	data := flattenChunks(want.Flattened())
+	eds, err := ImportExtendedDataSquareFromBuffer(data, ShardSize, want.codec, NewDefaultTree)
+	require.NoError(t, err)
+	assert.Equal(t, want.squareRow, eds.squareRow)
+	assertStored(t, eds, data)
+	roots, err := eds.RowRoots()
+	require.NoError(t, err)
+	assert.Equal(t, wantRoots, roots)
+
+	storage := make(byteStorage, len(data))
+	eds, err = ImportExtendedDataSquareFromBuffer(data, ShardSize, want.codec, NewDefaultTree, WithStorage(storage))
+	require.NoError(t, err)
+	assertStored(t, eds, storage)
+}
+
+func TestExtendedDataSquareFromBufferInvalid(t *testing.T) {
+	data := make([]byte, 4*ShardSize)
+	tests := []struct {
+		name      string
+		data      []byte
+		shareSize uint
+	}{
+		{"zero share size", data, 0},
+		{"uneven shares", data[1:], ShardSize},
+		{"not a square", data[:3*ShardSize], ShardSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ComputeExtendedDataSquareFromBuffer(tt.data, tt.shareSize, NewLeoRSCodec(), NewDefaultTree)
+			assert.Error(t, err)
+			_, err = ImportExtendedDataSquareFromBuffer(tt.data, tt.shareSize, NewLeoRSCodec(), NewDefaultTree)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWithStorageRepairAllocations(t *testing.T) {
+	want, err := ComputeExtendedDataSquare(genRandDS(16), NewLeoRSCodec(), NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := want.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := want.ColRoots()
+	require.NoError(t, err)
+
+	// drop the parity columns, so that every row is repaired
+	flattened := want.Flattened()
+	for i := range flattened {
+		if uint(i)%want.width >= want.originalDataWidth {
+			flattened[i] = nil
+		}
+	}
+	repair := func(opts ...Option) {
+		eds, err := ImportExtendedDataSquare(append([][]byte(nil), flattened...), want.codec, NewDefaultTree, opts...)
+		require.NoError(t, err)
+		require.NoError(t, eds.Repair(rowRoots, colRoots))
+		require.Equal(t, want.squareRow, eds.squareRow)
+	}
+
+	storage := make(byteStorage, len(flattenChunks(want.Flattened())))
+	allocs := testing.AllocsPerRun(5, func() { repair() })
+	storageAllocs := testing.AllocsPerRun(5, func() { repair(WithStorage(storage)) })
+	assert.Less(t, storageAllocs, allocs)
+}
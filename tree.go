@@ -1,6 +1,14 @@
 package rsmt2d
 
 import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sync"
+
 	"github.com/minio/sha256-simd"
 
 	"github.com/celestiaorg/merkletree"
@@ -22,19 +30,188 @@ type Tree interface {
 	Root() ([]byte, error)
 }
 
-var _ Tree = &DefaultTree{}
+// ProvableTree is an optional extension of Tree implemented by trees that can
+// produce and verify inclusion proofs for their leaves.
+type ProvableTree interface {
+	Tree
+	// Prove returns the proof that the pushed leaf at index is included in
+	// the root of the tree. The proof does not contain the leaf itself.
+	Prove(index uint) ([][]byte, error)
+	// VerifyProof returns true if proof shows that leaf is included at index
+	// in a tree with numLeaves leaves and the provided root. It does not
+	// depend on the leaves pushed to the tree.
+	VerifyProof(root []byte, leaf []byte, proof [][]byte, index uint, numLeaves uint) bool
+}
+
+// NamedTree is an optional extension of Tree implemented by trees that
+// identify how they compute roots, e.g. the hash function they use. The name is
+// recorded when serializing an ExtendedDataSquare so that it is not decoded
+// with a tree that computes different roots.
+type NamedTree interface {
+	Tree
+	// Name returns the name of the tree.
+	Name() string
+}
+
+// HasherTree is an optional extension of Tree implemented by trees that
+// compute their roots with a hash function. The data root of a
+// DataAvailabilityHeader is computed with the same hash function by
+// DataAvailabilityHeader.HashWithTree.
+type HasherTree interface {
+	Tree
+	// NewHash returns a new instance of the hash function of the tree.
+	NewHash() hash.Hash
+}
+
+// treeName returns the name of the trees created by fn, or the empty string if
+// they do not implement NamedTree.
+func treeName(fn TreeConstructorFn) string {
+	if tree, ok := fn(Row, 0).(NamedTree); ok {
+		return tree.Name()
+	}
+	return ""
+}
 
+var (
+	_ ProvableTree = &DefaultTree{}
+	_ NamedTree    = &DefaultTree{}
+	_ HasherTree   = &DefaultTree{}
+)
+
+// defaultTreeName is the name of DefaultTree with SHA-256.
+const defaultTreeName = "DefaultTree/sha256"
+
+// DefaultTree is a binary Merkle tree with the shape of RFC 6962 trees, where
+// leaves are hashed as H(0x00 || leaf) and inner nodes as
+// H(0x01 || left || right) so that a leaf cannot be confused with an inner
+// node. With domain tags, the tag of the row or column is inserted after the
+// prefix of every hash.
 type DefaultTree struct {
 	*merkletree.Tree
+	newHash func() hash.Hash
+	name    string
+	// tag identifies the row or column of the tree if domain tags are used.
+	tag    []byte
 	leaves [][]byte
 	root   []byte
 }
 
 func NewDefaultTree(_ Axis, _ uint) Tree {
+	return newDefaultTree(sha256.New, defaultTreeName)
+}
+
+// NewDefaultTreeWithHasher returns a TreeConstructorFn that creates
+// DefaultTrees hashing with the hash functions returned by newHash instead of
+// SHA-256. The name of the trees identifies the hash function, by its common
+// name for the SHA-2 family and by the digest of a fixed message otherwise, so
+// that a serialized square is not decoded with a tree using another hash.
+func NewDefaultTreeWithHasher(newHash func() hash.Hash) TreeConstructorFn {
+	return NewDefaultTreeWithOptions(WithTreeHasher(newHash))
+}
+
+// DefaultTreeOption configures the DefaultTrees created by the
+// TreeConstructorFn returned by NewDefaultTreeWithOptions.
+type DefaultTreeOption func(*defaultTreeConfig)
+
+type defaultTreeConfig struct {
+	newHash    func() hash.Hash
+	domainTags bool
+}
+
+// WithTreeHasher sets the hash function of the trees, SHA-256 by default.
+func WithTreeHasher(newHash func() hash.Hash) DefaultTreeOption {
+	return func(cfg *defaultTreeConfig) {
+		cfg.newHash = newHash
+	}
+}
+
+// WithDomainTags tags every leaf and inner node hash of a tree with the axis
+// and index of its row or column, i.e. hashes leaves as
+// H(0x00 || axis || index || leaf) and inner nodes as
+// H(0x01 || axis || index || left || right), where axis is a single byte and
+// index a big-endian uint64. A root or proof of one row or column then cannot
+// be used for another row or column.
+func WithDomainTags() DefaultTreeOption {
+	return func(cfg *defaultTreeConfig) {
+		cfg.domainTags = true
+	}
+}
+
+// NewDefaultTreeWithOptions returns a TreeConstructorFn that creates
+// DefaultTrees configured by opts. The name of the trees records the options.
+func NewDefaultTreeWithOptions(opts ...DefaultTreeOption) TreeConstructorFn {
+	cfg := defaultTreeConfig{newHash: sha256.New}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	name := "DefaultTree/" + hashName(cfg.newHash)
+	if cfg.domainTags {
+		name += "/tagged"
+	}
+	return func(axis Axis, index uint) Tree {
+		tree := newDefaultTree(cfg.newHash, name)
+		if cfg.domainTags {
+			tree.tag = domainTag(axis, index)
+		}
+		return tree
+	}
+}
+
+func newDefaultTree(newHash func() hash.Hash, name string) *DefaultTree {
 	return &DefaultTree{
-		Tree:   merkletree.New(sha256.New()),
-		leaves: make([][]byte, 0, 128),
+		Tree:    merkletree.New(newHash()),
+		newHash: newHash,
+		name:    name,
+		leaves:  make([][]byte, 0, 128),
+	}
+}
+
+// domainTag returns the tag of the tree of the row or column at index.
+func domainTag(axis Axis, index uint) []byte {
+	tag := make([]byte, 9)
+	tag[0] = byte(axis)
+	binary.BigEndian.PutUint64(tag[1:], uint64(index))
+	return tag
+}
+
+// hashFingerprintMessage is hashed to tell hash functions apart.
+var hashFingerprintMessage = []byte("rsmt2d hash fingerprint")
+
+var (
+	knownHashNamesOnce sync.Once
+	// knownHashNames maps the fingerprints of well-known hash functions to
+	// their names.
+	knownHashNames map[string]string
+)
+
+// hashFingerprint returns the hex encoded digest of hashFingerprintMessage,
+// truncated to 8 bytes.
+func hashFingerprint(newHash func() hash.Hash) string {
+	h := newHash()
+	h.Write(hashFingerprintMessage)
+	digest := h.Sum(nil)
+	if len(digest) > 8 {
+		digest = digest[:8]
 	}
+	return hex.EncodeToString(digest)
+}
+
+// hashName returns the name of the hash function returned by newHash.
+func hashName(newHash func() hash.Hash) string {
+	knownHashNamesOnce.Do(func() {
+		knownHashNames = map[string]string{
+			hashFingerprint(sha256.New):        "sha256",
+			hashFingerprint(sha512.New):        "sha512",
+			hashFingerprint(sha512.New384):     "sha384",
+			hashFingerprint(sha512.New512_224): "sha512/224",
+			hashFingerprint(sha512.New512_256): "sha512/256",
+		}
+	})
+	fingerprint := hashFingerprint(newHash)
+	if name, ok := knownHashNames[fingerprint]; ok {
+		return name
+	}
+	return "hash-" + fingerprint
 }
 
 func (d *DefaultTree) Push(data []byte) error {
@@ -44,7 +221,12 @@ func (d *DefaultTree) Push(data []byte) error {
 }
 
 func (d *DefaultTree) Root() ([]byte, error) {
-	if d.root == nil {
+	if d.root == nil && d.tag != nil {
+		// like merkletree, the root of an empty tree is nil
+		if len(d.leaves) > 0 {
+			d.root = merkleRoot(d.taggedLeafHashes(), d.hashTaggedNode)
+		}
+	} else if d.root == nil {
 		for _, l := range d.leaves {
 			d.Tree.Push(l)
 		}
@@ -52,3 +234,75 @@ func (d *DefaultTree) Root() ([]byte, error) {
 	}
 	return d.root, nil
 }
+
+// Name returns the name of the tree.
+func (d *DefaultTree) Name() string {
+	return d.name
+}
+
+// NewHash returns a new instance of the hash function of the tree.
+func (d *DefaultTree) NewHash() hash.Hash {
+	return d.newHash()
+}
+
+// Prove returns the Merkle proof for the leaf at index, computed with
+// merkletree's Prove.
+func (d *DefaultTree) Prove(index uint) ([][]byte, error) {
+	if index >= uint(len(d.leaves)) {
+		return nil, fmt.Errorf("cannot prove leaf %d of tree with %d leaves", index, len(d.leaves))
+	}
+	if d.tag != nil {
+		return merkleProof(d.taggedLeafHashes(), index, d.hashTaggedNode), nil
+	}
+
+	tree := merkletree.New(d.newHash())
+	if err := tree.SetIndex(uint64(index)); err != nil {
+		return nil, err
+	}
+	for _, l := range d.leaves {
+		tree.Push(l)
+	}
+	_, proofSet, _, _ := tree.Prove()
+	if len(proofSet) == 0 {
+		return nil, fmt.Errorf("failed to prove leaf %d", index)
+	}
+	// the first element of the proof set is the leaf itself
+	return proofSet[1:], nil
+}
+
+// VerifyProof verifies a Merkle proof created by Prove.
+func (d *DefaultTree) VerifyProof(root []byte, leaf []byte, proof [][]byte, index uint, numLeaves uint) bool {
+	if d.tag != nil {
+		computed, ok := merkleRootFromProof(d.hashTaggedLeaf(leaf), index, numLeaves, proof, d.hashTaggedNode)
+		return ok && bytes.Equal(computed, root)
+	}
+	proofSet := make([][]byte, 0, len(proof)+1)
+	proofSet = append(proofSet, leaf)
+	proofSet = append(proofSet, proof...)
+	return merkletree.VerifyProof(d.newHash(), root, proofSet, uint64(index), uint64(numLeaves))
+}
+
+func (d *DefaultTree) taggedLeafHashes() [][]byte {
+	leafHashes := make([][]byte, len(d.leaves))
+	for i, l := range d.leaves {
+		leafHashes[i] = d.hashTaggedLeaf(l)
+	}
+	return leafHashes
+}
+
+func (d *DefaultTree) hashTaggedLeaf(leaf []byte) []byte {
+	h := d.newHash()
+	h.Write([]byte{leafPrefix})
+	h.Write(d.tag)
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+func (d *DefaultTree) hashTaggedNode(left []byte, right []byte) []byte {
+	h := d.newHash()
+	h.Write([]byte{nodePrefix})
+	h.Write(d.tag)
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
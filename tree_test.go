@@ -0,0 +1,149 @@
+package rsmt2d
+
+import (
+	"crypto/sha512"
+	"hash"
+	"hash/fnv"
+	"testing"
+
+	"github.com/minio/sha256-simd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDefaultTreeWithHasher(t *testing.T) {
+	tests := []struct {
+		name    string
+		newHash func() hash.Hash
+		want    string
+	}{
+		{"sha256", sha256.New, defaultTreeName},
+		{"sha512", sha512.New, "DefaultTree/sha512"},
+		{"sha512/256", sha512.New512_256, "DefaultTree/sha512/256"},
+		{"fnv", func() hash.Hash { return fnv.New128a() }, "DefaultTree/hash-" + hashFingerprint(func() hash.Hash { return fnv.New128a() })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			treeFn := NewDefaultTreeWithHasher(tt.newHash)
+			assert.Equal(t, tt.want, treeName(treeFn))
+
+			eds, err := ComputeExtendedDataSquare(genRandDS(4), NewLeoRSCodec(), treeFn)
+			require.NoError(t, err)
+			rowRoots, err := eds.RowRoots()
+			require.NoError(t, err)
+			colRoots, err := eds.ColRoots()
+			require.NoError(t, err)
+			assert.Len(t, rowRoots[0], tt.newHash().Size())
+
+			proof, err := eds.ProveShare(Col, 1, 6)
+			require.NoError(t, err)
+			assert.NoError(t, proof.Verify(rowRoots, colRoots, treeFn))
+
+			defaultEds, err := ImportExtendedDataSquare(eds.Flattened(), NewLeoRSCodec(), NewDefaultTree)
+			require.NoError(t, err)
+			defaultRowRoots, err := defaultEds.RowRoots()
+			require.NoError(t, err)
+			if tt.want == defaultTreeName {
+				assert.Equal(t, defaultRowRoots, rowRoots)
+			} else {
+				assert.NotEqual(t, defaultRowRoots, rowRoots)
+				assert.Error(t, proof.Verify(rowRoots, colRoots, NewDefaultTree))
+			}
+		})
+	}
+}
+
+func TestDefaultTreeHasherEncoding(t *testing.T) {
+	treeFn := NewDefaultTreeWithHasher(sha512.New512_256)
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewLeoRSCodec(), treeFn)
+	require.NoError(t, err)
+	data, err := Encoder{IncludeRoots: true}.Marshal(eds)
+	require.NoError(t, err)
+
+	decoded, err := Decoder{TreeConstructor: treeFn}.Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, eds.squareRow, decoded.squareRow)
+
+	_, err = Decoder{}.Unmarshal(data)
+	assert.Error(t, err)
+	_, err = Decoder{TreeConstructor: NewDefaultTreeWithHasher(sha512.New512_224)}.Unmarshal(data)
+	assert.Error(t, err)
+}
+
+func TestDefaultTreeDomainSeparation(t *testing.T) {
+	leaves := [][]byte{{1}, {2}, {3}}
+	root := func(tree Tree, leaves ...[]byte) []byte {
+		for _, l := range leaves {
+			require.NoError(t, tree.Push(l))
+		}
+		root, err := tree.Root()
+		require.NoError(t, err)
+		return root
+	}
+
+	t.Run("leaves and inner nodes", func(t *testing.T) {
+		want := root(NewDefaultTree(Row, 0), leaves[0], leaves[1])
+		// the preimage of the root must not be accepted as a leaf
+		node := append(append([]byte{}, testHashLeaf(leaves[0])...), testHashLeaf(leaves[1])...)
+		assert.NotEqual(t, want, root(NewDefaultTree(Row, 0), node))
+		assert.Equal(t, testHashNode(testHashLeaf(leaves[0]), testHashLeaf(leaves[1])), want)
+	})
+
+	t.Run("domain tags", func(t *testing.T) {
+		treeFn := NewDefaultTreeWithOptions(WithDomainTags())
+		assert.Equal(t, defaultTreeName+"/tagged", treeName(treeFn))
+
+		roots := [][]byte{
+			root(NewDefaultTree(Row, 0), leaves...),
+			root(treeFn(Row, 0), leaves...),
+			root(treeFn(Row, 1), leaves...),
+			root(treeFn(Col, 0), leaves...),
+			root(NewDefaultTreeWithOptions(WithDomainTags(), WithTreeHasher(sha512.New512_256))(Row, 0), leaves...),
+		}
+		for i := range roots {
+			for j := i + 1; j < len(roots); j++ {
+				assert.NotEqual(t, roots[i], roots[j], "roots %d and %d", i, j)
+			}
+		}
+		assert.Equal(t, roots[1], root(treeFn(Row, 0), leaves...))
+
+		empty, err := treeFn(Row, 0).Root()
+		require.NoError(t, err)
+		assert.Nil(t, empty)
+	})
+
+	t.Run("tagged proofs", func(t *testing.T) {
+		treeFn := NewDefaultTreeWithOptions(WithDomainTags())
+		eds, err := ComputeExtendedDataSquare(genRandDS(4), NewLeoRSCodec(), treeFn)
+		require.NoError(t, err)
+		rowRoots, err := eds.RowRoots()
+		require.NoError(t, err)
+		colRoots, err := eds.ColRoots()
+		require.NoError(t, err)
+
+		for _, axis := range []Axis{Row, Col} {
+			for i := uint(0); i < eds.Width(); i++ {
+				for j := uint(0); j < eds.Width(); j++ {
+					proof, err := eds.ProveShare(axis, i, j)
+					require.NoError(t, err)
+					assert.NoError(t, proof.Verify(rowRoots, colRoots, treeFn))
+				}
+			}
+		}
+
+		proof, err := eds.ProveShare(Row, 2, 3)
+		require.NoError(t, err)
+		assert.Error(t, proof.Verify(rowRoots, colRoots, NewDefaultTree))
+		// the proof does not verify for the same share in the other axis
+		proof.Axis, proof.AxisIndex, proof.CellIndex = Col, 3, 2
+		assert.Error(t, proof.VerifyRoot(rowRoots[2], treeFn))
+
+		data, err := Encoder{IncludeRoots: true}.Marshal(eds)
+		require.NoError(t, err)
+		_, err = Decoder{}.Unmarshal(data)
+		assert.Error(t, err)
+		decoded, err := Decoder{TreeConstructor: treeFn}.Unmarshal(data)
+		require.NoError(t, err)
+		assert.Equal(t, eds.squareRow, decoded.squareRow)
+	})
+}
@@ -1,5 +1,11 @@
 package rsmt2d
 
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
 func flattenChunks(chunks [][]byte) []byte {
 	length := 0
 	for _, chunk := range chunks {
@@ -13,3 +19,49 @@ func flattenChunks(chunks [][]byte) []byte {
 
 	return flattened
 }
+
+// maxUint is the maximum value of a uint.
+const maxUint = uint64(^uint(0))
+
+// appendUvarint appends v to buf as a uvarint.
+func appendUvarint(buf []byte, v uint64) []byte {
+	return binary.AppendUvarint(buf, v)
+}
+
+// appendBytes appends b to buf, prefixed with its length as a uvarint.
+func appendBytes(buf []byte, b []byte) []byte {
+	buf = appendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// byteReader is the subset of *bytes.Reader used for decoding binary formats.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+	Len() int
+}
+
+// readUvarint reads a uvarint from r and returns an error if it exceeds max.
+func readUvarint(r byteReader, max uint64) (uint64, error) {
+	v, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, err
+	}
+	if v > max {
+		return 0, fmt.Errorf("value %d exceeds the maximum of %d", v, max)
+	}
+	return v, nil
+}
+
+// readBytes reads a byte slice written by appendBytes from r.
+func readBytes(r byteReader) ([]byte, error) {
+	length, err := readUvarint(r, uint64(r.Len()))
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
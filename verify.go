@@ -0,0 +1,50 @@
+package rsmt2d
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrIncompleteDataSquare is returned when verifying a square with missing
+// shares.
+var ErrIncompleteDataSquare = errors.New("data square is incomplete")
+
+// Verify checks that the square is complete, that every row and column
+// matches its root, and that the parity shares of every row and column are the
+// encoding of its original shares. Rows and columns are checked in parallel,
+// limited by the options the square was created with or the provided options.
+//
+// If the roots of a row or column don't match, an ErrRootMismatch for the row
+// with the lowest index, or else for the column with the lowest index, is
+// returned. If the roots match but some rows or columns are not encoded
+// correctly, all of them are returned in an ErrByzantineAxes, each of which can
+// be retrieved as an ErrByzantineData with errors.As.
+func (eds *ExtendedDataSquare) Verify(rowRoots [][]byte, colRoots [][]byte, opts ...Option) error {
+	if uint(len(rowRoots)) != eds.height || uint(len(colRoots)) != eds.width {
+		return fmt.Errorf("expected %d row roots and %d col roots, got %d and %d", eds.height, eds.width, len(rowRoots), len(colRoots))
+	}
+	return eds.verify(rowRoots, colRoots, opts)
+}
+
+// VerifyEncoding is like Verify, but only checks that the parity shares of
+// every row and column are the encoding of its original shares, without
+// comparing them to roots.
+func (eds *ExtendedDataSquare) VerifyEncoding(opts ...Option) error {
+	return eds.verify(nil, nil, opts)
+}
+
+func (eds *ExtendedDataSquare) verify(rowRoots [][]byte, colRoots [][]byte, opts []Option) error {
+	for r := uint(0); r < eds.height; r++ {
+		if !noMissingData(eds.row(r), noShareInsertion) {
+			return ErrIncompleteDataSquare
+		}
+	}
+
+	byzAxes := &byzantineAxes{}
+	err := eds.prerepairSanityCheck(context.Background(), eds.opts.with(opts), rowRoots, colRoots, byzAxes, nil)
+	if err != nil {
+		return err
+	}
+	return byzAxes.err()
+}
@@ -0,0 +1,98 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	for codecName, codec := range codecs {
+		t.Run(codecName, func(t *testing.T) {
+			eds, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+			require.NoError(t, err)
+			rowRoots, err := eds.RowRoots()
+			require.NoError(t, err)
+			colRoots, err := eds.ColRoots()
+			require.NoError(t, err)
+
+			t.Run("valid", func(t *testing.T) {
+				assert.NoError(t, eds.Verify(rowRoots, colRoots))
+				assert.NoError(t, eds.Verify(rowRoots, colRoots, WithMaxConcurrency(1)))
+				assert.NoError(t, eds.VerifyEncoding())
+			})
+
+			t.Run("wrong number of roots", func(t *testing.T) {
+				assert.Error(t, eds.Verify(rowRoots[1:], colRoots))
+			})
+
+			t.Run("incomplete", func(t *testing.T) {
+				incomplete, err := eds.deepCopy()
+				require.NoError(t, err)
+				incomplete.setCell(3, 5, nil)
+				assert.ErrorIs(t, incomplete.Verify(rowRoots, colRoots), ErrIncompleteDataSquare)
+				assert.ErrorIs(t, incomplete.VerifyEncoding(), ErrIncompleteDataSquare)
+			})
+
+			corrupted, err := eds.deepCopy()
+			require.NoError(t, err)
+			corrupted.setCell(1, 2, bytes.Repeat([]byte{66}, len(eds.GetCell(1, 2))))
+			corrupted.setCell(6, 5, bytes.Repeat([]byte{66}, len(eds.GetCell(6, 5))))
+
+			t.Run("root mismatch", func(t *testing.T) {
+				err := corrupted.Verify(rowRoots, colRoots)
+				var rootErr *ErrRootMismatch
+				require.ErrorAs(t, err, &rootErr)
+				assert.Equal(t, Row, rootErr.Axis)
+				assert.Equal(t, uint(1), rootErr.Index)
+				assert.Equal(t, rowRoots[1], rootErr.Expected)
+			})
+
+			t.Run("byzantine", func(t *testing.T) {
+				corruptedRowRoots, err := corrupted.RowRoots()
+				require.NoError(t, err)
+				corruptedColRoots, err := corrupted.ColRoots()
+				require.NoError(t, err)
+
+				for _, err := range []error{
+					corrupted.Verify(corruptedRowRoots, corruptedColRoots),
+					corrupted.VerifyEncoding(),
+				} {
+					var byzAxes *ErrByzantineAxes
+					require.ErrorAs(t, err, &byzAxes)
+					var got []ErrByzantineData
+					for _, byzData := range byzAxes.Errors {
+						got = append(got, ErrByzantineData{Axis: byzData.Axis, Index: byzData.Index})
+						assert.Len(t, byzData.Shares, int(corrupted.Width()))
+					}
+					assert.Equal(t, []ErrByzantineData{
+						{Axis: Row, Index: 1},
+						{Axis: Row, Index: 6},
+						{Axis: Col, Index: 2},
+						{Axis: Col, Index: 5},
+					}, got)
+
+					var byzData *ErrByzantineData
+					require.ErrorAs(t, err, &byzData)
+					assert.Equal(t, Row, byzData.Axis)
+					assert.Equal(t, uint(1), byzData.Index)
+				}
+			})
+		})
+	}
+}
+
+func TestVerifyRectangle(t *testing.T) {
+	codec := NewLeoRSCodec()
+	eds, err := ComputeExtendedDataRectangle(genRandDS(4)[:8], 2, codec, NewLeoRSCodec(WithExtensionFactor(4)), NewDefaultTree)
+	require.NoError(t, err)
+	rowRoots, err := eds.RowRoots()
+	require.NoError(t, err)
+	colRoots, err := eds.ColRoots()
+	require.NoError(t, err)
+
+	assert.NoError(t, eds.Verify(rowRoots, colRoots))
+	assert.NoError(t, eds.VerifyEncoding())
+}